@@ -0,0 +1,226 @@
+package yapdd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const defaultBatchParallelism = 4
+
+// BatchAction is a kind of operation queued in Batch
+type BatchAction string
+
+const (
+	BatchAdd  BatchAction = "add"
+	BatchEdit BatchAction = "edit"
+	BatchDel  BatchAction = "del"
+)
+
+// BatchOp is an operation queued in Batch
+type BatchOp struct {
+	Action   BatchAction
+	Domain   string
+	Type     DNSRecordType // for BatchAdd
+	RecordID uint32        // for BatchEdit and BatchDel
+	Params   *DNSRequestParams
+}
+
+// BatchResult is a result of BatchOp with the same index
+type BatchResult struct {
+	Op       BatchOp
+	Response *DNSResponse
+	Err      error
+	// Attempted is false if the operation was not started because the context was done
+	Attempted bool
+}
+
+// BatchOptions limit parallelism of Batch
+type BatchOptions struct {
+	// Parallelism is the maximum number of operations running at once, 4 by default
+	Parallelism int
+	// PerDomain is the maximum number of operations running at once for a single domain,
+	// equal to Parallelism by default. With PerDomain 1 operations of a domain run in the order they were queued.
+	PerDomain int
+}
+
+// BatchError is returned by Batch.Run if any operation failed or was not attempted
+type BatchError struct {
+	Failed       []int // indexes of operations which failed
+	NotAttempted []int // indexes of operations which were not started
+	// Err is the error of the first failed operation or the context error if none failed
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	s := fmt.Sprintf("batch: %d operations failed, %d not attempted", len(e.Failed), len(e.NotAttempted))
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// dnsMutator is implemented by Client and MultiClient
+type dnsMutator interface {
+	DNSAdd(ctx context.Context, domain string, recordType DNSRecordType, params *DNSRequestParams) (*DNSResponse, error)
+	DNSEdit(ctx context.Context, domain string, recordID uint32, params *DNSRequestParams) (*DNSResponse, error)
+	DNSDel(ctx context.Context, domain string, recordID uint32) (*DNSResponse, error)
+}
+
+// Batch queues DNS operations and runs them concurrently
+type Batch struct {
+	cli  dnsMutator
+	opts BatchOptions
+	ops  []BatchOp
+}
+
+// NewBatch returns an empty batch of operations made by the client
+func (c *Client) NewBatch(opts BatchOptions) *Batch {
+	return newBatch(c, opts)
+}
+
+// NewBatch returns an empty batch of operations routed by domain
+func (m *MultiClient) NewBatch(opts BatchOptions) *Batch {
+	return newBatch(m, opts)
+}
+
+func newBatch(cli dnsMutator, opts BatchOptions) *Batch {
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = defaultBatchParallelism
+	}
+	if opts.PerDomain <= 0 || opts.PerDomain > opts.Parallelism {
+		opts.PerDomain = opts.Parallelism
+	}
+	return &Batch{cli: cli, opts: opts}
+}
+
+// Add queues DNSAdd and returns the index of the operation
+func (b *Batch) Add(domain string, recordType DNSRecordType, params *DNSRequestParams) int {
+	return b.queue(BatchOp{Action: BatchAdd, Domain: domain, Type: recordType, Params: params})
+}
+
+// Edit queues DNSEdit and returns the index of the operation
+func (b *Batch) Edit(domain string, recordID uint32, params *DNSRequestParams) int {
+	return b.queue(BatchOp{Action: BatchEdit, Domain: domain, RecordID: recordID, Params: params})
+}
+
+// Del queues DNSDel and returns the index of the operation
+func (b *Batch) Del(domain string, recordID uint32) int {
+	return b.queue(BatchOp{Action: BatchDel, Domain: domain, RecordID: recordID})
+}
+
+func (b *Batch) queue(op BatchOp) int {
+	if op.Params != nil {
+		op.Params = op.Params.Clone()
+	}
+	b.ops = append(b.ops, op)
+	return len(b.ops) - 1
+}
+
+// Len returns the number of queued operations
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// Run runs all queued operations and returns their results in the order they were queued.
+// If ctx is done, operations which have not started yet are not attempted.
+// The error is *BatchError if any operation failed or was not attempted.
+func (b *Batch) Run(ctx context.Context) ([]BatchResult, error) {
+	results := make([]BatchResult, len(b.ops))
+
+	// operations are grouped by domain to limit per-domain parallelism
+	var domains []string
+	byDomain := map[string][]int{}
+	for i, op := range b.ops {
+		results[i].Op = op
+		d := normalizeDomain(op.Domain)
+		if _, ok := byDomain[d]; !ok {
+			domains = append(domains, d)
+		}
+		byDomain[d] = append(byDomain[d], i)
+	}
+
+	global := make(chan struct{}, b.opts.Parallelism)
+	var wg sync.WaitGroup
+	for _, d := range domains {
+		queue := make(chan int, len(byDomain[d]))
+		for _, i := range byDomain[d] {
+			queue <- i
+		}
+		close(queue)
+
+		for w := 0; w < b.opts.PerDomain && w < len(byDomain[d]); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range queue {
+					b.run(ctx, global, &results[i])
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	return results, batchError(results)
+}
+
+func (b *Batch) run(ctx context.Context, global chan struct{}, r *BatchResult) {
+	if err := ctx.Err(); err != nil {
+		r.Err = err
+		return
+	}
+
+	select {
+	case global <- struct{}{}:
+	case <-ctx.Done():
+		r.Err = ctx.Err()
+		return
+	}
+	defer func() {
+		<-global
+	}()
+
+	if err := ctx.Err(); err != nil {
+		r.Err = err
+		return
+	}
+
+	r.Attempted = true
+	switch r.Op.Action {
+	case BatchAdd:
+		r.Response, r.Err = b.cli.DNSAdd(ctx, r.Op.Domain, r.Op.Type, r.Op.Params)
+	case BatchEdit:
+		r.Response, r.Err = b.cli.DNSEdit(ctx, r.Op.Domain, r.Op.RecordID, r.Op.Params)
+	case BatchDel:
+		r.Response, r.Err = b.cli.DNSDel(ctx, r.Op.Domain, r.Op.RecordID)
+	default:
+		r.Err = fmt.Errorf("unknown batch action: %s", r.Op.Action)
+	}
+}
+
+func batchError(results []BatchResult) error {
+	var e BatchError
+	for i, r := range results {
+		switch {
+		case !r.Attempted:
+			e.NotAttempted = append(e.NotAttempted, i)
+		case r.Err != nil:
+			e.Failed = append(e.Failed, i)
+			if e.Err == nil {
+				e.Err = r.Err
+			}
+		}
+	}
+
+	if len(e.Failed) == 0 && len(e.NotAttempted) == 0 {
+		return nil
+	}
+	if e.Err == nil {
+		e.Err = results[e.NotAttempted[0]].Err
+	}
+	return &e
+}
@@ -0,0 +1,156 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyTransportMock answers DNS mutations after delay, fails ones with content "fail"
+// and records the maximum number of requests in flight
+type concurrencyTransportMock struct {
+	delay time.Duration
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	domains     map[string]int
+	maxDomain   map[string]int
+	order       []string
+}
+
+func (m *concurrencyTransportMock) RoundTrip(r *http.Request) (*http.Response, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	domain := r.PostForm.Get("domain")
+
+	m.mu.Lock()
+	m.inFlight++
+	m.domains[domain]++
+	if m.inFlight > m.maxInFlight {
+		m.maxInFlight = m.inFlight
+	}
+	if m.domains[domain] > m.maxDomain[domain] {
+		m.maxDomain[domain] = m.domains[domain]
+	}
+	m.order = append(m.order, domain+" "+r.PostForm.Get("content"))
+	m.mu.Unlock()
+
+	time.Sleep(m.delay)
+
+	m.mu.Lock()
+	m.inFlight--
+	m.domains[domain]--
+	m.mu.Unlock()
+
+	body := fmt.Sprintf(`{"domain": %q, "record": {"content": %q}, "success": "ok"}`, domain, r.PostForm.Get("content"))
+	if r.PostForm.Get("content") == "fail" {
+		body = `{"success": "error", "error": "bad_domain"}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func newConcurrencyTransportMock(delay time.Duration) *concurrencyTransportMock {
+	return &concurrencyTransportMock{
+		delay:     delay,
+		domains:   map[string]int{},
+		maxDomain: map[string]int{},
+	}
+}
+
+func TestBatch_Run(t *testing.T) {
+	transport := newConcurrencyTransportMock(10 * time.Millisecond)
+	cli := New("token", WithHTTPClient(&http.Client{Transport: transport}))
+
+	b := cli.NewBatch(BatchOptions{Parallelism: 3, PerDomain: 1})
+	params := NewDNSParams()
+	for i := 0; i < 4; i++ {
+		for _, d := range []string{"a.com", "b.com", "c.com", "d.com"} {
+			b.Add(d, DNSTypeA, params.Content(fmt.Sprintf("127.0.0.%d", i)))
+		}
+	}
+	failed := b.Add("a.com", DNSTypeTXT, NewDNSParams().Content("fail"))
+
+	results, err := b.Run(context.Background())
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected BatchError, got: %v", err)
+	}
+	if !reflect.DeepEqual([]int{failed}, batchErr.Failed) || len(batchErr.NotAttempted) != 0 {
+		t.Errorf("unexpected batch error: %+v", batchErr)
+	}
+	if !errors.Is(err, ErrBadDomain) {
+		t.Errorf("expected error of failed operation, got: %v", err)
+	}
+
+	for i, r := range results[:failed] {
+		expContent := fmt.Sprintf("127.0.0.%d", i/4)
+		if !r.Attempted || r.Err != nil || r.Response.Record.Content != expContent {
+			t.Errorf("unexpected result %d: %+v", i, r)
+		}
+	}
+
+	if transport.maxInFlight > 3 {
+		t.Errorf("expected at most 3 requests in flight, got: %d", transport.maxInFlight)
+	}
+	for d, n := range transport.maxDomain {
+		if n > 1 {
+			t.Errorf("expected at most 1 request in flight for %s, got: %d", d, n)
+		}
+	}
+
+	var aOrder []string
+	for _, o := range transport.order {
+		if strings.HasPrefix(o, "a.com ") {
+			aOrder = append(aOrder, o)
+		}
+	}
+	expOrder := []string{"a.com 127.0.0.0", "a.com 127.0.0.1", "a.com 127.0.0.2", "a.com 127.0.0.3", "a.com fail"}
+	if !reflect.DeepEqual(expOrder, aOrder) {
+		t.Errorf("expected order: %v, got: %v", expOrder, aOrder)
+	}
+}
+
+func TestBatch_RunCanceled(t *testing.T) {
+	transport := newConcurrencyTransportMock(20 * time.Millisecond)
+	cli := New("token", WithHTTPClient(&http.Client{Transport: transport}))
+
+	b := cli.NewBatch(BatchOptions{Parallelism: 1})
+	for i := 0; i < 5; i++ {
+		b.Del("domain.com", uint32(i+1))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	results, err := b.Run(ctx)
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected BatchError, got: %v", err)
+	}
+	if len(batchErr.NotAttempted) == 0 {
+		t.Fatalf("expected not attempted operations")
+	}
+	for _, i := range batchErr.NotAttempted {
+		if results[i].Attempted || !errors.Is(results[i].Err, context.DeadlineExceeded) {
+			t.Errorf("unexpected result %d: %+v", i, results[i])
+		}
+	}
+	if !results[0].Attempted {
+		t.Errorf("expected the first operation to be attempted")
+	}
+}
@@ -0,0 +1,202 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultBreakerFailures    = 5
+	defaultBreakerOpenTimeout = 30 * time.Second
+)
+
+// CircuitState is a state of the circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests with *CircuitOpenError
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial requests through
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerSettings configures the circuit breaker
+type CircuitBreakerSettings struct {
+	// Failures is the number of consecutive failures opening the circuit, 5 by default.
+	// A failure is a transport error or HTTP status 5xx.
+	Failures int
+	// OpenTimeout is the time the circuit stays open before trial requests are let through, 30s by default
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of concurrent trial requests in half-open state, 1 by default.
+	// A successful trial closes the circuit, a failed one opens it again.
+	HalfOpenRequests int
+	// OnStateChange is called on every change of state
+	OnStateChange func(from, to CircuitState)
+}
+
+// ErrCircuitOpen is a target for errors.Is matching *CircuitOpenError
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned without sending a request while the circuit is open
+type CircuitOpenError struct {
+	Until time.Time // time when trial requests are let through
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("pdd circuit breaker is open until %s", e.Until.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// WithCircuitBreaker makes the client fail fast after consecutive failures of PDD
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	return func(cli *Client) {
+		if settings.Failures <= 0 {
+			settings.Failures = defaultBreakerFailures
+		}
+		if settings.OpenTimeout <= 0 {
+			settings.OpenTimeout = defaultBreakerOpenTimeout
+		}
+		if settings.HalfOpenRequests <= 0 {
+			settings.HalfOpenRequests = 1
+		}
+		cli.breaker = &circuitBreaker{settings: settings, now: time.Now}
+	}
+}
+
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trials   int
+}
+
+// transition is a change of state reported after the lock is released
+type transition struct {
+	from, to CircuitState
+}
+
+// allow reports whether a request may be sent and whether it is a trial one
+func (b *circuitBreaker) allow() (trial bool, err error) {
+	var changes []transition
+
+	b.mu.Lock()
+	if b.state == CircuitOpen {
+		until := b.openedAt.Add(b.settings.OpenTimeout)
+		if b.now().Before(until) {
+			b.mu.Unlock()
+			return false, &CircuitOpenError{Until: until}
+		}
+		changes = b.setState(changes, CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.trials >= b.settings.HalfOpenRequests {
+			until := b.now()
+			b.mu.Unlock()
+			b.notify(changes)
+			return false, &CircuitOpenError{Until: until}
+		}
+		b.trials++
+		trial = true
+	}
+	b.mu.Unlock()
+
+	b.notify(changes)
+	return trial, nil
+}
+
+// done records the result of a request allowed by allow.
+// Only requests which were sent count as successes or failures.
+func (b *circuitBreaker) done(ctx context.Context, trial, sent bool, err error) {
+	var changes []transition
+
+	b.mu.Lock()
+	stale := trial != (b.state == CircuitHalfOpen)
+	if trial && !stale {
+		b.trials--
+	}
+
+	switch {
+	case stale:
+		// the state changed while the request was in flight
+	case ctx.Err() != nil:
+		// the request was canceled by caller, it tells nothing about PDD
+	case !sent:
+		// the request failed before sending, e.g. a token source or the rate limiter failed
+	case isBreakerFailure(err):
+		b.failures++
+		if trial || b.failures >= b.settings.Failures {
+			b.openedAt = b.now()
+			changes = b.setState(changes, CircuitOpen)
+		}
+	default:
+		b.failures = 0
+		if trial {
+			changes = b.setState(changes, CircuitClosed)
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(changes)
+}
+
+func (b *circuitBreaker) setState(changes []transition, state CircuitState) []transition {
+	if state == CircuitHalfOpen || state == CircuitClosed {
+		b.trials = 0
+	}
+	if state == CircuitClosed {
+		b.failures = 0
+	}
+	changes = append(changes, transition{from: b.state, to: state})
+	b.state = state
+	return changes
+}
+
+func (b *circuitBreaker) notify(changes []transition) {
+	if b.settings.OnStateChange == nil {
+		return
+	}
+	for _, t := range changes {
+		b.settings.OnStateChange(t.from, t.to)
+	}
+}
+
+// isBreakerFailure reports whether err means that PDD is unavailable
+func isBreakerFailure(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,193 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	listOK := mockResponse{status: http.StatusOK, body: `{"domain": "domain.com", "records": [], "success": "ok"}`}
+	unavailable := mockResponse{status: http.StatusServiceUnavailable}
+	notFound := mockResponse{status: http.StatusNotFound}
+	apiError := mockResponse{status: http.StatusOK, body: `{"success": "error", "error": "no_auth"}`}
+	resetErr := mockResponse{err: errors.New("connection reset")}
+	const tokenErr = "can't get PDD token: helper failed"
+
+	cases := []struct {
+		name        string
+		steps       []time.Duration // time passed before every call
+		tokenErrs   []bool          // calls failing to get a token before sending
+		responses   []mockResponse
+		expErrs     []string
+		expChanges  []string
+		expRequests int
+	}{
+		{
+			name:        "opens after consecutive failures",
+			steps:       []time.Duration{0, 0, 0, 0},
+			responses:   []mockResponse{unavailable, resetErr, unavailable},
+			expErrs:     []string{"http", "url", "http", "open"},
+			expChanges:  []string{"closed->open"},
+			expRequests: 3,
+		},
+		{
+			name:        "success resets failures",
+			steps:       []time.Duration{0, 0, 0, 0, 0},
+			responses:   []mockResponse{unavailable, unavailable, listOK, unavailable, unavailable},
+			expErrs:     []string{"http", "http", "", "http", "http"},
+			expRequests: 5,
+		},
+		{
+			name:        "client errors are not failures",
+			steps:       []time.Duration{0, 0, 0, 0},
+			responses:   []mockResponse{notFound, apiError, notFound, apiError},
+			expErrs:     []string{"http", "api", "http", "api"},
+			expRequests: 4,
+		},
+		{
+			name:        "successful trial closes",
+			steps:       []time.Duration{0, 0, 0, 10 * time.Second, 21 * time.Second, 0},
+			responses:   []mockResponse{unavailable, unavailable, unavailable, listOK, listOK},
+			expErrs:     []string{"http", "http", "http", "open", "", ""},
+			expChanges:  []string{"closed->open", "open->half-open", "half-open->closed"},
+			expRequests: 5,
+		},
+		{
+			name:        "failed trial opens again",
+			steps:       []time.Duration{0, 0, 0, 30 * time.Second, 0},
+			responses:   []mockResponse{unavailable, unavailable, unavailable, unavailable},
+			expErrs:     []string{"http", "http", "http", "http", "open"},
+			expChanges:  []string{"closed->open", "open->half-open", "half-open->open"},
+			expRequests: 4,
+		},
+		{
+			name:        "errors before sending are not failures",
+			steps:       []time.Duration{0, 0, 0, 0},
+			tokenErrs:   []bool{false, false, true, false},
+			responses:   []mockResponse{unavailable, unavailable, unavailable},
+			expErrs:     []string{"http", "http", tokenErr, "http"},
+			expChanges:  []string{"closed->open"},
+			expRequests: 3,
+		},
+		{
+			name:        "trial failed before sending does not close",
+			steps:       []time.Duration{0, 0, 0, 30 * time.Second, 0},
+			tokenErrs:   []bool{false, false, false, true, false},
+			responses:   []mockResponse{unavailable, unavailable, unavailable, listOK},
+			expErrs:     []string{"http", "http", "http", tokenErr, ""},
+			expChanges:  []string{"closed->open", "open->half-open", "half-open->closed"},
+			expRequests: 4,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &sequenceTransportMock{responses: tc.responses}
+
+			var call int
+			tokens := TokenSourceFunc(func(context.Context) (string, error) {
+				if call < len(tc.tokenErrs) && tc.tokenErrs[call] {
+					return "", errors.New("helper failed")
+				}
+				return "token", nil
+			})
+
+			var changes []string
+			cli := New("",
+				WithTokenSource(tokens),
+				WithHTTPClient(&http.Client{Transport: transport}),
+				WithCircuitBreaker(CircuitBreakerSettings{
+					Failures:    3,
+					OpenTimeout: 30 * time.Second,
+					OnStateChange: func(from, to CircuitState) {
+						changes = append(changes, fmt.Sprintf("%s->%s", from, to))
+					},
+				}),
+			)
+			current := now
+			cli.breaker.now = func() time.Time { return current }
+
+			var errs []string
+			for i, d := range tc.steps {
+				call = i
+				current = current.Add(d)
+				_, err := cli.DNSList(context.Background(), "domain.com")
+				errs = append(errs, errorKind(err))
+			}
+
+			if !reflect.DeepEqual(tc.expErrs, errs) {
+				t.Errorf("expected errors: %v, got: %v", tc.expErrs, errs)
+			}
+			if !reflect.DeepEqual(tc.expChanges, changes) {
+				t.Errorf("expected state changes: %v, got: %v", tc.expChanges, changes)
+			}
+			if len(transport.requests) != tc.expRequests {
+				t.Errorf("expected %d requests, got: %d", tc.expRequests, len(transport.requests))
+			}
+		})
+	}
+}
+
+func errorKind(err error) string {
+	var httpErr *HTTPError
+	var apiErr *APIError
+	var openErr *CircuitOpenError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &openErr):
+		if !errors.Is(err, ErrCircuitOpen) {
+			return "open without ErrCircuitOpen"
+		}
+		return "open"
+	case errors.As(err, &httpErr):
+		return "http"
+	case errors.As(err, &apiErr):
+		return "api"
+	case isBreakerFailure(err):
+		return "url"
+	}
+	return err.Error()
+}
+
+func TestCircuitBreaker_HalfOpenRequests(t *testing.T) {
+	b := &circuitBreaker{
+		settings: CircuitBreakerSettings{Failures: 1, OpenTimeout: time.Second, HalfOpenRequests: 2},
+		now:      time.Now,
+		state:    CircuitOpen,
+	}
+
+	trial1, err1 := b.allow()
+	trial2, err2 := b.allow()
+	_, err3 := b.allow()
+	if !trial1 || !trial2 || err1 != nil || err2 != nil {
+		t.Fatalf("expected two trial requests, got: %v, %v", err1, err2)
+	}
+	if !errors.Is(err3, ErrCircuitOpen) {
+		t.Fatalf("expected the third request to be rejected, got: %v", err3)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.done(ctx, true, true, ctx.Err())
+	if trial, err := b.allow(); !trial || err != nil {
+		t.Fatalf("expected a trial request after a canceled one, got: %v", err)
+	}
+
+	b.done(context.Background(), true, true, nil)
+	if b.state != CircuitClosed {
+		t.Errorf("expected closed state, got: %s", b.state)
+	}
+
+	// a trial finished after the circuit has been closed changes nothing
+	b.done(context.Background(), true, true, &HTTPError{StatusCode: http.StatusBadGateway})
+	if b.state != CircuitClosed || b.failures != 0 {
+		t.Errorf("expected closed state without failures, got: %s, %d", b.state, b.failures)
+	}
+}
@@ -0,0 +1,30 @@
+package yapdd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// Call makes a request to any PDD API method, e.g. section "email" and action "list",
+// with the same authentication, error handling, retries and middleware as typed methods.
+// The response is decoded into out, which may be nil if only the result status is needed.
+// Method is GET if empty. Calls other than GET are not retried.
+func (c *Client) Call(ctx context.Context, section, action, method string, params url.Values, out interface{}) error {
+	if method == "" {
+		method = http.MethodGet
+	}
+	if out == nil {
+		out = &json.RawMessage{}
+	}
+
+	return c.do(ctx, &Operation{
+		Name:    "Call",
+		Method:  method,
+		Section: section,
+		Action:  action,
+		Domain:  params.Get("domain"),
+		Params:  params,
+	}, out)
+}
@@ -0,0 +1,105 @@
+package yapdd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type emailListResponse struct {
+	Domain   string `json:"domain"`
+	Accounts []struct {
+		Login string `json:"login"`
+	} `json:"accounts"`
+	Success string `json:"success"`
+}
+
+func TestClient_Call(t *testing.T) {
+	unavailable := mockResponse{status: http.StatusServiceUnavailable}
+
+	cases := []struct {
+		name        string
+		opts        []Option
+		responses   []mockResponse
+		call        func(*Client) (interface{}, error)
+		expErr      error
+		expResponse interface{}
+		expRequests []string
+	}{
+		{
+			name: "get is retried",
+			opts: []Option{WithRetry(RetryPolicy{MinBackoff: time.Millisecond})},
+			responses: []mockResponse{
+				unavailable,
+				{status: http.StatusOK, body: `{"domain": "domain.com", "accounts": [{"login": "user@domain.com"}], "success": "ok"}`},
+			},
+			call: func(c *Client) (interface{}, error) {
+				var r emailListResponse
+				err := c.Call(context.Background(), "email", "list", "", url.Values{"domain": {"domain.com"}}, &r)
+				return &r, err
+			},
+			expResponse: &emailListResponse{
+				Domain: "domain.com",
+				Accounts: []struct {
+					Login string `json:"login"`
+				}{{Login: "user@domain.com"}},
+				Success: "ok",
+			},
+			expRequests: []string{
+				"GET https://pddimp.yandex.ru/api2/admin/email/list?domain=domain.com",
+				"GET https://pddimp.yandex.ru/api2/admin/email/list?domain=domain.com",
+			},
+		},
+		{
+			name:      "post is not retried",
+			opts:      []Option{WithRetry(RetryPolicy{MinBackoff: time.Millisecond, Mutations: true}), AsRegistrar("oauth")},
+			responses: []mockResponse{unavailable},
+			call: func(c *Client) (interface{}, error) {
+				return nil, c.Call(context.Background(), "email", "del", http.MethodPost, url.Values{"domain": {"domain.com"}}, nil)
+			},
+			expErr: &HTTPError{
+				StatusCode: http.StatusServiceUnavailable,
+				Status:     "503 Service Unavailable",
+				Method:     http.MethodPost,
+				URL:        "https://pddimp.yandex.ru/api2/registrar/email/del",
+			},
+			expRequests: []string{
+				"POST https://pddimp.yandex.ru/api2/registrar/email/del",
+			},
+		},
+		{
+			name:      "api error",
+			responses: []mockResponse{{status: http.StatusOK, body: `{"success": "error", "error": "no_auth"}`}},
+			call: func(c *Client) (interface{}, error) {
+				return nil, c.Call(context.Background(), "email", "add", http.MethodPost, url.Values{"domain": {"domain.com"}}, nil)
+			},
+			expErr: &APIError{Section: "email", Action: "add", Domain: "domain.com", Code: ErrNoAuth},
+			expRequests: []string{
+				"POST https://pddimp.yandex.ru/api2/admin/email/add",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &sequenceTransportMock{responses: tc.responses}
+			opts := append([]Option{WithHTTPClient(&http.Client{Transport: transport})}, tc.opts...)
+			cli := New("token", opts...)
+
+			response, err := tc.call(cli)
+			if fmt.Sprint(tc.expErr) != fmt.Sprint(err) {
+				t.Errorf("expected error: %v, got: %v", tc.expErr, err)
+			}
+			if tc.expResponse != nil && !reflect.DeepEqual(tc.expResponse, response) {
+				t.Errorf("expected response: %+v, got: %+v", tc.expResponse, response)
+			}
+			if !reflect.DeepEqual(tc.expRequests, transport.requests) {
+				t.Errorf("expected requests: %v, got: %v", tc.expRequests, transport.requests)
+			}
+		})
+	}
+}
@@ -2,8 +2,14 @@ package yapdd
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 )
 
 type DNSRecordType string
@@ -25,18 +31,18 @@ type DNSPriority struct {
 }
 
 func (p *DNSPriority) UnmarshalJSON(b []byte) error {
-	if string(b) == `""` {
+	if string(b) == `""` || string(b) == "null" {
 		p.value = 0
 		p.ok = false
 		return nil
 	}
 
-	i, err := strconv.Atoi(string(b))
-	if err != nil {
+	var v jsonUint
+	if err := v.unmarshal(b, 16); err != nil {
 		return err
 	}
 
-	p.value = uint16(i)
+	p.value = uint16(v)
 	p.ok = true
 	return nil
 }
@@ -46,92 +52,364 @@ func (p *DNSPriority) Get() (uint16, bool) {
 }
 
 type DNSRecord struct {
-	ID        uint32        `json:"record_id"`
-	Type      DNSRecordType `json:"type"`
+	ID        uint32        `json:"record_id" yapdd:"required"`
+	Type      DNSRecordType `json:"type" yapdd:"required"`
 	Domain    string        `json:"domain"`
 	Subdomain string        `json:"subdomain"`
 	FQDN      string        `json:"fqdn"`
 	TTL       uint32        `json:"ttl"`
-	Content   string        `json:"content"`
+	Content   string        `json:"content" yapdd:"required"`
 	Priority  DNSPriority   `json:"priority"`
 	Operation string        `json:"operation"`
+
+	// fields of SRV records
+	Weight uint16 `json:"weight,omitempty"`
+	Port   uint16 `json:"port,omitempty"`
+
+	// fields of SOA records
+	AdminMail string `json:"admin_mail,omitempty"`
+	Refresh   uint32 `json:"refresh,omitempty"`
+	Retry     uint32 `json:"retry,omitempty"`
+	Expire    uint32 `json:"expire,omitempty"`
+	MinTTL    uint32 `json:"minttl,omitempty"`
+}
+
+// UnmarshalJSON accepts numeric fields encoded both as numbers and as strings
+func (rec *DNSRecord) UnmarshalJSON(b []byte) error {
+	type record DNSRecord
+	aux := struct {
+		*record
+		ID      jsonUint `json:"record_id"`
+		TTL     jsonUint `json:"ttl"`
+		Weight  jsonUint `json:"weight"`
+		Port    jsonUint `json:"port"`
+		Refresh jsonUint `json:"refresh"`
+		Retry   jsonUint `json:"retry"`
+		Expire  jsonUint `json:"expire"`
+		MinTTL  jsonUint `json:"minttl"`
+	}{
+		record:  (*record)(rec),
+		ID:      jsonUint(rec.ID),
+		TTL:     jsonUint(rec.TTL),
+		Weight:  jsonUint(rec.Weight),
+		Port:    jsonUint(rec.Port),
+		Refresh: jsonUint(rec.Refresh),
+		Retry:   jsonUint(rec.Retry),
+		Expire:  jsonUint(rec.Expire),
+		MinTTL:  jsonUint(rec.MinTTL),
+	}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	if aux.Weight > math.MaxUint16 || aux.Port > math.MaxUint16 {
+		return fmt.Errorf("weight %d or port %d is out of range", aux.Weight, aux.Port)
+	}
+
+	rec.ID = uint32(aux.ID)
+	rec.TTL = uint32(aux.TTL)
+	rec.Weight = uint16(aux.Weight)
+	rec.Port = uint16(aux.Port)
+	rec.Refresh = uint32(aux.Refresh)
+	rec.Retry = uint32(aux.Retry)
+	rec.Expire = uint32(aux.Expire)
+	rec.MinTTL = uint32(aux.MinTTL)
+	return nil
 }
 
 type DNSResponse struct {
-	Domain   string     `json:"domain"`
+	Domain   string     `json:"domain" yapdd:"required"`
 	RecordID uint32     `json:"record_id"`
 	Record   *DNSRecord `json:"record"`
-	Success  string     `json:"success"`
+	Success  string     `json:"success" yapdd:"required"`
 	Error    string     `json:"error"`
 }
 
+// UnmarshalJSON accepts numeric fields encoded both as numbers and as strings
+func (r *DNSResponse) UnmarshalJSON(b []byte) error {
+	type response DNSResponse
+	aux := struct {
+		*response
+		RecordID jsonUint `json:"record_id"`
+	}{
+		response: (*response)(r),
+		RecordID: jsonUint(r.RecordID),
+	}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	r.RecordID = uint32(aux.RecordID)
+	return nil
+}
+
 type DNSListResponse struct {
-	Domain  string       `json:"domain"`
-	Records []*DNSRecord `json:"records"`
-	Success string       `json:"success"`
+	Domain  string       `json:"domain" yapdd:"required"`
+	Records []*DNSRecord `json:"records" yapdd:"required"`
+	Success string       `json:"success" yapdd:"required"`
 	Error   string       `json:"error"`
 }
 
-func (c *Client) DNSAdd(ctx context.Context, domain string, recordType DNSRecordType, params *DNSRequestParams) (*DNSResponse, error) {
-	params = params.recordType(recordType).domain(domain)
-	req, err := http.NewRequest(
-		http.MethodPost,
-		c.getURL("dns", "add", nil),
-		params.body(),
-	)
+// jsonUint is an unsigned 32-bit number encoded either as a JSON number or as a string
+type jsonUint uint32
+
+func (u *jsonUint) UnmarshalJSON(b []byte) error {
+	return u.unmarshal(b, 32)
+}
+
+func (u *jsonUint) unmarshal(b []byte, bitSize int) error {
+	s := string(b)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		s = strings.TrimSpace(s)
+	}
+	if s == "" || s == "null" {
+		return nil
+	}
+
+	v, err := strconv.ParseUint(s, 10, bitSize)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("invalid unsigned number %s: %w", b, err)
 	}
 
+	*u = jsonUint(v)
+	return nil
+}
+
+// DNSAdd adds a record. Params are not changed and may be nil.
+func (c *Client) DNSAdd(ctx context.Context, domain string, recordType DNSRecordType, params *DNSRequestParams) (*DNSResponse, error) {
+	params = params.Clone()
+
 	var r DNSResponse
-	err = c.do(ctx, req, &r)
+	if !c.noValidation {
+		if err := validateRecord(recordType, url.Values(*params)); err != nil {
+			return &r, err
+		}
+	}
+
+	params = params.recordType(recordType).domain(domain)
+	err := c.do(ctx, &Operation{
+		Name:    "DNSAdd",
+		Method:  http.MethodPost,
+		Section: "dns",
+		Action:  "add",
+		Domain:  domain,
+		Params:  url.Values(*params),
+		landed: func(ctx context.Context, v interface{}) (bool, error) {
+			return c.dnsAdded(ctx, domain, params, v.(*DNSResponse))
+		},
+		simulate: func(ctx context.Context, v interface{}) error {
+			return c.simulateDNSAdd(ctx, domain, recordType, params, v.(*DNSResponse))
+		},
+	}, &r)
 	return &r, err
 }
 
 func (c *Client) DNSList(ctx context.Context, domain string) (*DNSListResponse, error) {
-	params := NewDNSParams().domain(domain)
-	req, err := http.NewRequest(
-		http.MethodGet,
-		c.getURL("dns", "list", params),
-		nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-
 	var r DNSListResponse
-	err = c.do(ctx, req, &r)
+	err := c.do(ctx, &Operation{
+		Name:    "DNSList",
+		Method:  http.MethodGet,
+		Section: "dns",
+		Action:  "list",
+		Domain:  domain,
+		Params:  url.Values(*NewDNSParams().domain(domain)),
+	}, &r)
 	return &r, err
 }
 
+// DNSEdit changes values of the record with recordID. Params are not changed and may be nil.
 func (c *Client) DNSEdit(ctx context.Context, domain string, recordID uint32, params *DNSRequestParams) (*DNSResponse, error) {
-	params = params.recordID(recordID).domain(domain)
-	req, err := http.NewRequest(
-		http.MethodPost,
-		c.getURL("dns", "edit", nil),
-		params.body(),
-	)
-	if err != nil {
-		return nil, err
-	}
+	params = params.Clone()
 
 	var r DNSResponse
-	err = c.do(ctx, req, &r)
+	if !c.noValidation {
+		if err := validateRecord("", url.Values(*params)); err != nil {
+			return &r, err
+		}
+	}
+
+	params = params.recordID(recordID).domain(domain)
+	err := c.do(ctx, &Operation{
+		Name:    "DNSEdit",
+		Method:  http.MethodPost,
+		Section: "dns",
+		Action:  "edit",
+		Domain:  domain,
+		Params:  url.Values(*params),
+		landed: func(ctx context.Context, v interface{}) (bool, error) {
+			return c.dnsEdited(ctx, domain, recordID, params, v.(*DNSResponse))
+		},
+		simulate: func(ctx context.Context, v interface{}) error {
+			return c.simulateDNSEdit(ctx, domain, recordID, params, v.(*DNSResponse))
+		},
+	}, &r)
 	return &r, err
 }
 
 func (c *Client) DNSDel(ctx context.Context, domain string, recordID uint32) (*DNSResponse, error) {
-	params := NewDNSParams().recordID(recordID).domain(domain)
-	req, err := http.NewRequest(
-		http.MethodPost,
-		c.getURL("dns", "del", nil),
-		params.body(),
-	)
+	var r DNSResponse
+	err := c.do(ctx, &Operation{
+		Name:    "DNSDel",
+		Method:  http.MethodPost,
+		Section: "dns",
+		Action:  "del",
+		Domain:  domain,
+		Params:  url.Values(*NewDNSParams().recordID(recordID).domain(domain)),
+		landed: func(ctx context.Context, v interface{}) (bool, error) {
+			return c.dnsDeleted(ctx, domain, recordID, v.(*DNSResponse))
+		},
+		simulate: func(ctx context.Context, v interface{}) error {
+			return c.simulateDNSDel(ctx, domain, recordID, v.(*DNSResponse))
+		},
+	}, &r)
+	return &r, err
+}
+
+// dnsAdded checks whether a record described by params exists in domain
+func (c *Client) dnsAdded(ctx context.Context, domain string, params *DNSRequestParams, r *DNSResponse) (bool, error) {
+	values := url.Values(*params)
+	if _, ok := values["subdomain"]; !ok {
+		values = url.Values{"subdomain": {"@"}}
+		for k, v := range *params {
+			values[k] = v
+		}
+	}
+
+	list, err := c.DNSList(ctx, domain)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	var r DNSResponse
-	err = c.do(ctx, req, &r)
-	return &r, err
+	for _, rec := range list.Records {
+		if rec.matches(values) {
+			r.Domain = domain
+			r.Record = rec
+			r.Success = successOK
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// dnsEdited checks whether a record with recordID has values from params
+func (c *Client) dnsEdited(ctx context.Context, domain string, recordID uint32, params *DNSRequestParams, r *DNSResponse) (bool, error) {
+	list, err := c.DNSList(ctx, domain)
+	if err != nil {
+		return false, err
+	}
+
+	for _, rec := range list.Records {
+		if rec.ID == recordID {
+			if !rec.matches(url.Values(*params)) {
+				return false, nil
+			}
+			r.Domain = domain
+			r.Record = rec
+			r.Success = successOK
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// dnsDeleted checks whether a record with recordID is absent in domain
+func (c *Client) dnsDeleted(ctx context.Context, domain string, recordID uint32, r *DNSResponse) (bool, error) {
+	list, err := c.DNSList(ctx, domain)
+	if err != nil {
+		return false, err
+	}
+
+	for _, rec := range list.Records {
+		if rec.ID == recordID {
+			return false, nil
+		}
+	}
+
+	r.Domain = domain
+	r.RecordID = recordID
+	r.Success = successOK
+	return true, nil
+}
+
+// matches reports whether record has type, subdomain, content, TTL, priority, SRV and SOA fields
+// equal to ones set in values. Parameters absent in values are not compared.
+func (rec *DNSRecord) matches(values url.Values) bool {
+	if t, ok := values["type"]; ok && !strings.EqualFold(t[0], string(rec.Type)) {
+		return false
+	}
+
+	if s, ok := values["subdomain"]; ok && normalizeSubdomain(s[0]) != normalizeSubdomain(rec.Subdomain) {
+		return false
+	}
+
+	content, ok := values["content"]
+	if rec.Type == DNSTypeSRV {
+		content, ok = values["target"]
+	}
+	if ok && !sameContent(rec.Type, content[0], rec.Content) {
+		return false
+	}
+
+	if ttl, ok := values["ttl"]; ok && ttl[0] != strconv.Itoa(int(rec.TTL)) {
+		return false
+	}
+
+	if priority, ok := values["priority"]; ok {
+		p, set := rec.Priority.Get()
+		if !set || priority[0] != strconv.Itoa(int(p)) {
+			return false
+		}
+	}
+
+	if mail, ok := values["admin_mail"]; ok && !strings.EqualFold(mail[0], rec.AdminMail) {
+		return false
+	}
+
+	numbers := []struct {
+		key   string
+		value uint64
+	}{
+		{"weight", uint64(rec.Weight)},
+		{"port", uint64(rec.Port)},
+		{"refresh", uint64(rec.Refresh)},
+		{"retry", uint64(rec.Retry)},
+		{"expire", uint64(rec.Expire)},
+		{"neg_cache", uint64(rec.MinTTL)},
+	}
+	for _, n := range numbers {
+		if v, ok := values[n.key]; ok && v[0] != strconv.FormatUint(n.value, 10) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func normalizeSubdomain(s string) string {
+	s = strings.ToLower(strings.TrimSuffix(s, "."))
+	if s == "" {
+		return "@"
+	}
+	return s
+}
+
+func sameContent(t DNSRecordType, c1, c2 string) bool {
+	switch t {
+	case DNSTypeA, DNSTypeAAAA:
+		ip1, ip2 := net.ParseIP(c1), net.ParseIP(c2)
+		if ip1 != nil && ip2 != nil {
+			return ip1.Equal(ip2)
+		}
+		return c1 == c2
+	case DNSTypeTXT:
+		return c1 == c2
+	default:
+		return strings.EqualFold(strings.TrimSuffix(c1, "."), strings.TrimSuffix(c2, "."))
+	}
 }
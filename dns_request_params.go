@@ -79,7 +79,7 @@ func (p *DNSRequestParams) SetRetry(retry uint32) *DNSRequestParams {
 	return p
 }
 
-func (p *DNSRequestParams) Expire(expire uint16) *DNSRequestParams {
+func (p *DNSRequestParams) Expire(expire uint32) *DNSRequestParams {
 	url.Values(*p).Set("expire", strconv.Itoa(int(expire)))
 	return p
 }
@@ -89,6 +89,114 @@ func (p *DNSRequestParams) NegCache(negcache uint32) *DNSRequestParams {
 	return p
 }
 
+// Clone returns a copy of params which can be changed independently. Clone of nil is empty params.
+func (p *DNSRequestParams) Clone() *DNSRequestParams {
+	c := NewDNSParams()
+	if p == nil {
+		return c
+	}
+	for k, v := range *p {
+		(*c)[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// Merge sets all values of other to params and returns params. Merge into nil returns a copy of other.
+func (p *DNSRequestParams) Merge(other *DNSRequestParams) *DNSRequestParams {
+	if p == nil {
+		return other.Clone()
+	}
+	if other == nil {
+		return p
+	}
+	for k, v := range *other {
+		(*p)[k] = append([]string(nil), v...)
+	}
+	return p
+}
+
+// Validate checks params of a record of recordType the same way DNSAdd does.
+// Only fields independent of type are checked if recordType is empty, as DNSEdit does.
+func (p *DNSRequestParams) Validate(recordType DNSRecordType) error {
+	return validateRecord(recordType, url.Values(*p.Clone()))
+}
+
+func (p *DNSRequestParams) get(key string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	v, ok := (*p)[key]
+	if !ok || len(v) == 0 {
+		return "", false
+	}
+	return v[0], true
+}
+
+func (p *DNSRequestParams) getUint(key string, bitSize int) (uint64, bool) {
+	s, ok := p.get(key)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s, 10, bitSize)
+	return v, err == nil
+}
+
+func (p *DNSRequestParams) GetAdminMail() (string, bool) {
+	return p.get("admin_mail")
+}
+
+func (p *DNSRequestParams) GetContent() (string, bool) {
+	return p.get("content")
+}
+
+func (p *DNSRequestParams) GetPriority() (uint16, bool) {
+	v, ok := p.getUint("priority", 16)
+	return uint16(v), ok
+}
+
+func (p *DNSRequestParams) GetWeight() (uint16, bool) {
+	v, ok := p.getUint("weight", 16)
+	return uint16(v), ok
+}
+
+func (p *DNSRequestParams) GetPort() (uint16, bool) {
+	v, ok := p.getUint("port", 16)
+	return uint16(v), ok
+}
+
+func (p *DNSRequestParams) GetTarget() (string, bool) {
+	return p.get("target")
+}
+
+func (p *DNSRequestParams) GetSubdomain() (string, bool) {
+	return p.get("subdomain")
+}
+
+func (p *DNSRequestParams) GetTTL() (uint32, bool) {
+	v, ok := p.getUint("ttl", 32)
+	return uint32(v), ok
+}
+
+func (p *DNSRequestParams) GetRefresh() (uint32, bool) {
+	v, ok := p.getUint("refresh", 32)
+	return uint32(v), ok
+}
+
+func (p *DNSRequestParams) GetRetry() (uint32, bool) {
+	v, ok := p.getUint("retry", 32)
+	return uint32(v), ok
+}
+
+func (p *DNSRequestParams) GetExpire() (uint32, bool) {
+	v, ok := p.getUint("expire", 32)
+	return uint32(v), ok
+}
+
+func (p *DNSRequestParams) GetNegCache() (uint32, bool) {
+	v, ok := p.getUint("neg_cache", 32)
+	return uint32(v), ok
+}
+
 func (p *DNSRequestParams) body() io.Reader {
 	return strings.NewReader(url.Values(*p).Encode())
 }
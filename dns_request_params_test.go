@@ -3,6 +3,7 @@ package yapdd
 import (
 	"bytes"
 	"io/ioutil"
+	"reflect"
 	"testing"
 )
 
@@ -37,3 +38,96 @@ func TestDNSRequestParams_SetAllParams(t *testing.T) {
 		t.Errorf("\nexpected body:\n%s\ngot:\n%s", expBody, body)
 	}
 }
+
+func TestDNSRequestParams_CloneMerge(t *testing.T) {
+	template := NewDNSParams().Subdomain("www").TTL(900)
+
+	clone := template.Clone().Content("1.2.3.4")
+	if _, ok := template.GetContent(); ok {
+		t.Errorf("expected template without content")
+	}
+
+	merged := template.Clone().Merge(NewDNSParams().TTL(3600).Priority(10))
+	if ttl, _ := merged.GetTTL(); ttl != 3600 {
+		t.Errorf("expected merged ttl 3600, got: %d", ttl)
+	}
+	if ttl, _ := template.GetTTL(); ttl != 900 {
+		t.Errorf("expected template ttl 900, got: %d", ttl)
+	}
+	if subdomain, _ := merged.GetSubdomain(); subdomain != "www" {
+		t.Errorf("expected merged subdomain www, got: %s", subdomain)
+	}
+
+	var nilParams *DNSRequestParams
+	if c := nilParams.Clone(); c == nil || len(*c) != 0 {
+		t.Errorf("expected empty clone of nil, got: %v", c)
+	}
+	if m := nilParams.Merge(clone); !reflect.DeepEqual(clone, m) || m == clone {
+		t.Errorf("expected copy of merged params, got: %v", m)
+	}
+	if _, ok := nilParams.GetContent(); ok {
+		t.Errorf("expected no content in nil params")
+	}
+	if err := nilParams.Validate(""); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestDNSRequestParams_Getters(t *testing.T) {
+	params := NewDNSParams().
+		AdminMail("admin@domain.com").
+		Content("content").
+		Expire(2).
+		NegCache(3).
+		Port(4).
+		Priority(5).
+		Refresh(6).
+		SetRetry(7).
+		Weight(8).
+		Target("target").
+		Subdomain("www").
+		TTL(9)
+
+	strs := map[string]func() (string, bool){
+		"admin@domain.com": params.GetAdminMail,
+		"content":          params.GetContent,
+		"target":           params.GetTarget,
+		"www":              params.GetSubdomain,
+	}
+	for exp, get := range strs {
+		if v, ok := get(); !ok || v != exp {
+			t.Errorf("expected %s, got: %s, %t", exp, v, ok)
+		}
+	}
+
+	uint16s := map[uint16]func() (uint16, bool){
+		4: params.GetPort,
+		5: params.GetPriority,
+		8: params.GetWeight,
+	}
+	for exp, get := range uint16s {
+		if v, ok := get(); !ok || v != exp {
+			t.Errorf("expected %d, got: %d, %t", exp, v, ok)
+		}
+	}
+
+	uint32s := map[uint32]func() (uint32, bool){
+		2: params.GetExpire,
+		3: params.GetNegCache,
+		6: params.GetRefresh,
+		7: params.GetRetry,
+		9: params.GetTTL,
+	}
+	for exp, get := range uint32s {
+		if v, ok := get(); !ok || v != exp {
+			t.Errorf("expected %d, got: %d, %t", exp, v, ok)
+		}
+	}
+
+	if _, ok := (&DNSRequestParams{"ttl": {"x"}}).GetTTL(); ok {
+		t.Errorf("expected invalid ttl not to be returned")
+	}
+	if err := NewDNSParams().Content("::1").Validate(DNSTypeA); err == nil {
+		t.Errorf("expected validation error")
+	}
+}
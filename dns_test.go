@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -122,7 +123,36 @@ func TestClient_DNSAdd(t *testing.T) {
 			name:        "fail: transport returned error",
 			httpErr:     errors.New("fail"),
 			expResponse: &DNSResponse{},
-			expErr:      errors.New("Post https://pddimp.yandex.ru/api2/admin/dns/add: fail"),
+			expErr:      &url.Error{Op: "Post", URL: "https://pddimp.yandex.ru/api2/admin/dns/add", Err: errors.New("fail")},
+			expHTTPRequest: getRequest(
+				t,
+				http.MethodPost,
+				"https://pddimp.yandex.ru/api2/admin/dns/add",
+				"content=1.2.3.4&domain=domain.com&subdomain=www&type=A",
+				map[string][]string{
+					"PddToken":     {"token"},
+					"Content-Type": {"application/x-www-form-urlencoded"},
+				},
+			),
+		},
+		{
+			name: "fail: api returned error",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(strings.NewReader(`
+					{
+					  "domain": "domain.com",
+					  "success": "error",
+					  "error": "no_auth"
+					}
+				`)),
+			},
+			expResponse: &DNSResponse{
+				Domain:  "domain.com",
+				Success: "error",
+				Error:   "no_auth",
+			},
+			expErr: &APIError{Section: "dns", Action: "add", Domain: "domain.com", Code: ErrNoAuth},
 			expHTTPRequest: getRequest(
 				t,
 				http.MethodPost,
@@ -278,7 +308,36 @@ func TestClient_DNSList(t *testing.T) {
 			name:        "fail: transport returned error",
 			httpErr:     errors.New("fail"),
 			expResponse: &DNSListResponse{},
-			expErr:      errors.New("Get https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com: fail"),
+			expErr:      &url.Error{Op: "Get", URL: "https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com", Err: errors.New("fail")},
+			expHTTPRequest: getRequest(
+				t,
+				http.MethodGet,
+				"https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com",
+				"",
+				map[string][]string{
+					"PddToken":     {"token"},
+					"Content-Type": {"application/x-www-form-urlencoded"},
+				},
+			),
+		},
+		{
+			name: "fail: api returned error",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(strings.NewReader(`
+					{
+					  "domain": "domain.com",
+					  "success": "error",
+					  "error": "no_auth"
+					}
+				`)),
+			},
+			expResponse: &DNSListResponse{
+				Domain:  "domain.com",
+				Success: "error",
+				Error:   "no_auth",
+			},
+			expErr: &APIError{Section: "dns", Action: "list", Domain: "domain.com", Code: ErrNoAuth},
 			expHTTPRequest: getRequest(
 				t,
 				http.MethodGet,
@@ -407,7 +466,7 @@ func TestClient_DNSEdit(t *testing.T) {
 			name:        "fail: transport returned error",
 			httpErr:     errors.New("fail"),
 			expResponse: &DNSResponse{},
-			expErr:      errors.New("Post https://pddimp.yandex.ru/api2/admin/dns/edit: fail"),
+			expErr:      &url.Error{Op: "Post", URL: "https://pddimp.yandex.ru/api2/admin/dns/edit", Err: errors.New("fail")},
 			expHTTPRequest: getRequest(
 				t,
 				http.MethodPost,
@@ -427,7 +486,41 @@ func TestClient_DNSEdit(t *testing.T) {
 				Body:       ioutil.NopCloser(strings.NewReader("")),
 			},
 			expResponse: &DNSResponse{},
-			expErr:      errors.New("unexpected response status: 503 Service Unavailable"),
+			expErr: &HTTPError{
+				StatusCode: http.StatusServiceUnavailable,
+				Status:     "503 Service Unavailable",
+				Method:     http.MethodPost,
+				URL:        "https://pddimp.yandex.ru/api2/admin/dns/edit",
+			},
+			expHTTPRequest: getRequest(
+				t,
+				http.MethodPost,
+				"https://pddimp.yandex.ru/api2/admin/dns/edit",
+				"content=1.2.3.4&domain=domain.com&record_id=1&subdomain=www",
+				map[string][]string{
+					"PddToken":     {"token"},
+					"Content-Type": {"application/x-www-form-urlencoded"},
+				},
+			),
+		},
+		{
+			name: "fail: api returned error",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(strings.NewReader(`
+					{
+					  "domain": "domain.com",
+					  "success": "error",
+					  "error": "no_auth"
+					}
+				`)),
+			},
+			expResponse: &DNSResponse{
+				Domain:  "domain.com",
+				Success: "error",
+				Error:   "no_auth",
+			},
+			expErr: &APIError{Section: "dns", Action: "edit", Domain: "domain.com", Code: ErrNoAuth},
 			expHTTPRequest: getRequest(
 				t,
 				http.MethodPost,
@@ -537,7 +630,36 @@ func TestClient_DNSDel(t *testing.T) {
 			name:        "fail: transport returned error",
 			httpErr:     errors.New("fail"),
 			expResponse: &DNSResponse{},
-			expErr:      errors.New("Post https://pddimp.yandex.ru/api2/admin/dns/del: fail"),
+			expErr:      &url.Error{Op: "Post", URL: "https://pddimp.yandex.ru/api2/admin/dns/del", Err: errors.New("fail")},
+			expHTTPRequest: getRequest(
+				t,
+				http.MethodPost,
+				"https://pddimp.yandex.ru/api2/admin/dns/del",
+				"domain=domain.com&record_id=1",
+				map[string][]string{
+					"PddToken":     {"token"},
+					"Content-Type": {"application/x-www-form-urlencoded"},
+				},
+			),
+		},
+		{
+			name: "fail: api returned error",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(strings.NewReader(`
+					{
+					  "domain": "domain.com",
+					  "success": "error",
+					  "error": "no_auth"
+					}
+				`)),
+			},
+			expResponse: &DNSResponse{
+				Domain:  "domain.com",
+				Success: "error",
+				Error:   "no_auth",
+			},
+			expErr: &APIError{Section: "dns", Action: "del", Domain: "domain.com", Code: ErrNoAuth},
 			expHTTPRequest: getRequest(
 				t,
 				http.MethodPost,
@@ -646,3 +768,31 @@ func requestsEqual(r1, r2 *http.Request) (bool, error) {
 
 	return false, nil
 }
+
+func TestClient_DNSParamsNotChanged(t *testing.T) {
+	transport := &sequenceTransportMock{}
+	for i := 0; i < 3; i++ {
+		transport.responses = append(transport.responses, mockResponse{status: http.StatusOK, body: `{"success": "ok"}`})
+	}
+	cli := New("token", WithHTTPClient(&http.Client{Transport: transport}))
+
+	template := NewDNSParams().Subdomain("www").Content("1.2.3.4")
+	if _, err := cli.DNSAdd(context.Background(), "domain.com", DNSTypeA, template); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := cli.DNSEdit(context.Background(), "other.com", 1, template); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expTemplate := NewDNSParams().Subdomain("www").Content("1.2.3.4")
+	if !reflect.DeepEqual(expTemplate, template) {
+		t.Errorf("expected params: %v, got: %v", expTemplate, template)
+	}
+
+	if _, err := cli.DNSEdit(context.Background(), "domain.com", 1, nil); err != nil {
+		t.Errorf("unexpected error with nil params: %s", err)
+	}
+	if _, err := cli.DNSAdd(context.Background(), "domain.com", DNSTypeA, nil); err == nil {
+		t.Errorf("expected validation error of nil params for A record")
+	}
+}
@@ -0,0 +1,46 @@
+package yapdd
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type Domain struct {
+	Name        string   `json:"name" yapdd:"required"`
+	Status      string   `json:"status"`
+	NSDelegated bool     `json:"nsdelegated"`
+	Aliases     []string `json:"aliases"`
+}
+
+type DomainListResponse struct {
+	Page    int       `json:"page"`
+	OnPage  int       `json:"on_page"`
+	Total   int       `json:"total"`
+	Found   int       `json:"found"`
+	Domains []*Domain `json:"domains" yapdd:"required"`
+	Success string    `json:"success" yapdd:"required"`
+	Error   string    `json:"error"`
+}
+
+// DomainList returns a page of domains of the account. Pages are numbered from 1.
+func (c *Client) DomainList(ctx context.Context, page, onPage int) (*DomainListResponse, error) {
+	params := url.Values{}
+	if page > 0 {
+		params.Set("page", strconv.Itoa(page))
+	}
+	if onPage > 0 {
+		params.Set("on_page", strconv.Itoa(onPage))
+	}
+
+	var r DomainListResponse
+	err := c.do(ctx, &Operation{
+		Name:    "DomainList",
+		Method:  http.MethodGet,
+		Section: "domain",
+		Action:  "domains",
+		Params:  params,
+	}, &r)
+	return &r, err
+}
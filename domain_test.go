@@ -0,0 +1,110 @@
+package yapdd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_DomainList(t *testing.T) {
+	cases := []struct {
+		name           string
+		httpResponse   *http.Response
+		expErr         error
+		expResponse    *DomainListResponse
+		expHTTPRequest *http.Request
+	}{
+		{
+			name: "success",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(strings.NewReader(`
+					{
+					  "page": 2,
+					  "on_page": 1,
+					  "domains": [
+					    {
+					      "name": "domain.com",
+					      "status": "added",
+					      "nsdelegated": true,
+					      "aliases": ["alias.com"]
+					    }
+					  ],
+					  "total": 3,
+					  "found": 3,
+					  "success": "ok"
+					}
+				`)),
+			},
+			expResponse: &DomainListResponse{
+				Page:   2,
+				OnPage: 1,
+				Domains: []*Domain{
+					{Name: "domain.com", Status: "added", NSDelegated: true, Aliases: []string{"alias.com"}},
+				},
+				Total:   3,
+				Found:   3,
+				Success: "ok",
+			},
+			expHTTPRequest: getRequest(
+				t,
+				http.MethodGet,
+				"https://pddimp.yandex.ru/api2/admin/domain/domains?on_page=1&page=2",
+				"",
+				map[string][]string{
+					"PddToken":     {"token"},
+					"Content-Type": {"application/x-www-form-urlencoded"},
+				},
+			),
+		},
+		{
+			name: "fail: api returned error",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"success": "error", "error": "bad_token"}`)),
+			},
+			expResponse: &DomainListResponse{Success: "error", Error: "bad_token"},
+			expErr:      &APIError{Section: "domain", Action: "domains", Code: ErrBadToken},
+			expHTTPRequest: getRequest(
+				t,
+				http.MethodGet,
+				"https://pddimp.yandex.ru/api2/admin/domain/domains?on_page=1&page=2",
+				"",
+				map[string][]string{
+					"PddToken":     {"token"},
+					"Content-Type": {"application/x-www-form-urlencoded"},
+				},
+			),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(*testing.T) {
+			transport := &httpTransportMock{
+				response: tc.httpResponse,
+			}
+
+			cli := New("token", WithHTTPClient(&http.Client{Transport: transport}))
+
+			response, err := cli.DomainList(context.Background(), 2, 1)
+			if fmt.Sprint(tc.expErr) != fmt.Sprint(err) {
+				t.Errorf("expected error: %v, got: %v", tc.expErr, err)
+			}
+			if !reflect.DeepEqual(tc.expResponse, response) {
+				t.Errorf("expected response: %+v, got: %+v", tc.expResponse, response)
+			}
+
+			ok, err := requestsEqual(tc.expHTTPRequest, transport.request)
+			if err != nil {
+				t.Fatalf("error reading body of request: %s", err)
+			}
+			if !ok {
+				t.Errorf("expected request:\n%+v,\ngot:\n%+v", tc.expHTTPRequest, transport.request)
+			}
+		})
+	}
+}
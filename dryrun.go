@@ -0,0 +1,182 @@
+package yapdd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultDNSTTL is TTL PDD sets for records added without it
+const defaultDNSTTL = 21600
+
+// WithDryRun makes the client validate and log calls changing data instead of sending them.
+// Such calls return a response synthesized from the current state returned by DNSList.
+// Calls reading data are sent as usual.
+func WithDryRun() Option {
+	return func(cli *Client) {
+		cli.dryRun = true
+	}
+}
+
+func (c *Client) dryRunOperation(ctx context.Context, op *Operation, v interface{}) error {
+	tokens, err := c.tokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newHTTPRequest(c.baseURL, op)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	c.setHeaders(req, op, tokens)
+
+	if op.simulate != nil {
+		err = op.simulate(ctx, v)
+	} else {
+		err = json.Unmarshal([]byte(`{"success": "ok"}`), v)
+	}
+
+	if c.logger != nil {
+		c.logExchange(ctx, op, req, &exchange{dryRun: true}, err)
+	}
+
+	return err
+}
+
+func (c *Client) simulateDNSAdd(ctx context.Context, domain string, recordType DNSRecordType, params *DNSRequestParams, r *DNSResponse) error {
+	if err := validateDryRun(domain); err != nil {
+		return err
+	}
+
+	// the list is requested to check that the domain is available with current credentials
+	if _, err := c.DNSList(ctx, domain); err != nil {
+		return err
+	}
+
+	rec := &DNSRecord{
+		Type:      recordType,
+		Domain:    domain,
+		Subdomain: "@",
+		TTL:       defaultDNSTTL,
+	}
+	rec.apply(url.Values(*params))
+
+	r.Domain = domain
+	r.Record = rec
+	r.Success = successOK
+	return nil
+}
+
+func (c *Client) simulateDNSEdit(ctx context.Context, domain string, recordID uint32, params *DNSRequestParams, r *DNSResponse) error {
+	if err := validateDryRun(domain); err != nil {
+		return err
+	}
+
+	rec, err := c.dnsRecord(ctx, domain, recordID, "edit")
+	if err != nil {
+		return err
+	}
+
+	edited := *rec
+	edited.apply(url.Values(*params))
+
+	r.Domain = domain
+	r.Record = &edited
+	r.Success = successOK
+	return nil
+}
+
+func (c *Client) simulateDNSDel(ctx context.Context, domain string, recordID uint32, r *DNSResponse) error {
+	if err := validateDryRun(domain); err != nil {
+		return err
+	}
+
+	if _, err := c.dnsRecord(ctx, domain, recordID, "del"); err != nil {
+		return err
+	}
+
+	r.Domain = domain
+	r.RecordID = recordID
+	r.Success = successOK
+	return nil
+}
+
+// dnsRecord returns the record with recordID from DNSList
+// or APIError with code ErrNotFound if it is absent
+func (c *Client) dnsRecord(ctx context.Context, domain string, recordID uint32, action string) (*DNSRecord, error) {
+	list, err := c.DNSList(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rec := range list.Records {
+		if rec.ID == recordID {
+			return rec, nil
+		}
+	}
+
+	return nil, &APIError{Section: "dns", Action: action, Domain: domain, Code: ErrNotFound}
+}
+
+// validateDryRun checks the domain only, records are checked by validateRecord
+// in DNSAdd and DNSEdit unless the client is created WithoutValidation
+func validateDryRun(domain string) error {
+	if domain == "" {
+		return errors.New("domain is required")
+	}
+	return nil
+}
+
+func (t DNSRecordType) valid() bool {
+	switch t {
+	case DNSTypeSRV, DNSTypeTXT, DNSTypeNS, DNSTypeMX, DNSTypeSOA, DNSTypeA, DNSTypeAAAA, DNSTypeCNAME:
+		return true
+	}
+	return false
+}
+
+// apply sets record fields to values of parameters
+func (rec *DNSRecord) apply(values url.Values) {
+	if t, ok := values["type"]; ok {
+		rec.Type = DNSRecordType(t[0])
+	}
+	if s, ok := values["subdomain"]; ok {
+		rec.Subdomain = normalizeSubdomain(s[0])
+	}
+	if c, ok := values["content"]; ok {
+		rec.Content = c[0]
+	}
+	if t, ok := values["target"]; ok && rec.Type == DNSTypeSRV {
+		rec.Content = t[0]
+	}
+	if ttl, ok := values["ttl"]; ok {
+		if v, err := strconv.ParseUint(ttl[0], 10, 32); err == nil {
+			rec.TTL = uint32(v)
+		}
+	}
+	if p, ok := values["priority"]; ok {
+		if v, err := strconv.ParseUint(p[0], 10, 16); err == nil {
+			rec.Priority = DNSPriority{value: uint16(v), ok: true}
+		}
+	}
+	if w, ok := values["weight"]; ok {
+		if v, err := strconv.ParseUint(w[0], 10, 16); err == nil {
+			rec.Weight = uint16(v)
+		}
+	}
+	if p, ok := values["port"]; ok {
+		if v, err := strconv.ParseUint(p[0], 10, 16); err == nil {
+			rec.Port = uint16(v)
+		}
+	}
+
+	rec.FQDN = rec.Domain
+	if rec.Subdomain != "@" {
+		rec.FQDN = rec.Subdomain + "." + rec.Domain
+	}
+	rec.FQDN = strings.ToLower(rec.FQDN)
+}
@@ -0,0 +1,141 @@
+package yapdd
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_DryRun(t *testing.T) {
+	const (
+		listURL = "GET https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com"
+		list    = `{"domain": "domain.com", "records": [{"record_id": 1, "type": "A", "domain": "domain.com", "subdomain": "www", "fqdn": "www.domain.com", "content": "1.2.3.4", "ttl": 900, "priority": ""}], "success": "ok"}`
+	)
+
+	cases := []struct {
+		name        string
+		call        func(*Client) (*DNSResponse, error)
+		expErr      error
+		expResponse *DNSResponse
+		expBody     string
+	}{
+		{
+			name: "add",
+			call: func(c *Client) (*DNSResponse, error) {
+				return c.DNSAdd(context.Background(), "domain.com", DNSTypeMX, NewDNSParams().Content("mx.domain.com").Priority(10))
+			},
+			expResponse: &DNSResponse{
+				Domain: "domain.com",
+				Record: &DNSRecord{
+					Type:      DNSTypeMX,
+					Domain:    "domain.com",
+					Subdomain: "@",
+					FQDN:      "domain.com",
+					TTL:       defaultDNSTTL,
+					Content:   "mx.domain.com",
+					Priority:  DNSPriority{value: 10, ok: true},
+				},
+				Success: "ok",
+			},
+			expBody: "content=mx.domain.com&domain=domain.com&priority=10&type=MX",
+		},
+		{
+			name: "edit",
+			call: func(c *Client) (*DNSResponse, error) {
+				return c.DNSEdit(context.Background(), "domain.com", 1, NewDNSParams().Subdomain("mail").Content("4.3.2.1"))
+			},
+			expResponse: &DNSResponse{
+				Domain: "domain.com",
+				Record: &DNSRecord{
+					ID:        1,
+					Type:      DNSTypeA,
+					Domain:    "domain.com",
+					Subdomain: "mail",
+					FQDN:      "mail.domain.com",
+					TTL:       900,
+					Content:   "4.3.2.1",
+				},
+				Success: "ok",
+			},
+			expBody: "content=4.3.2.1&domain=domain.com&record_id=1&subdomain=mail",
+		},
+		{
+			name: "edit of absent record",
+			call: func(c *Client) (*DNSResponse, error) {
+				return c.DNSEdit(context.Background(), "domain.com", 2, NewDNSParams().Content("4.3.2.1"))
+			},
+			expErr:      &APIError{Section: "dns", Action: "edit", Domain: "domain.com", Code: ErrNotFound},
+			expResponse: &DNSResponse{},
+			expBody:     "content=4.3.2.1&domain=domain.com&record_id=2",
+		},
+		{
+			name: "del",
+			call: func(c *Client) (*DNSResponse, error) {
+				return c.DNSDel(context.Background(), "domain.com", 1)
+			},
+			expResponse: &DNSResponse{Domain: "domain.com", RecordID: 1, Success: "ok"},
+			expBody:     "domain=domain.com&record_id=1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &sequenceTransportMock{
+				responses: []mockResponse{{status: http.StatusOK, body: list}},
+			}
+
+			var events []*LogEvent
+			cli := New(
+				"token",
+				WithHTTPClient(&http.Client{Transport: transport}),
+				WithDryRun(),
+				WithLogger(LoggerFunc(func(_ context.Context, e *LogEvent) {
+					events = append(events, e)
+				}), LogLevelInfo),
+			)
+
+			response, err := tc.call(cli)
+			if !reflect.DeepEqual(tc.expErr, err) {
+				t.Errorf("expected error: %v, got: %v", tc.expErr, err)
+			}
+			if !reflect.DeepEqual(tc.expResponse, response) {
+				t.Errorf("expected response: %+v, got: %+v", tc.expResponse, response)
+			}
+			if !reflect.DeepEqual([]string{listURL}, transport.requests) {
+				t.Errorf("expected only list request, got: %v", transport.requests)
+			}
+
+			if len(events) != 2 {
+				t.Fatalf("expected 2 log events, got: %d", len(events))
+			}
+			e := events[1]
+			if !e.DryRun || e.Method != http.MethodPost || e.RequestBody != tc.expBody {
+				t.Errorf("unexpected dry-run event: %+v", e)
+			}
+			if v := e.RequestHeader["PddToken"]; !reflect.DeepEqual(v, []string{"REDACTED"}) {
+				t.Errorf("expected redacted token in dry-run event, got: %v", v)
+			}
+		})
+	}
+}
+
+func TestClient_DryRunWithoutValidation(t *testing.T) {
+	transport := &sequenceTransportMock{
+		responses: []mockResponse{{status: http.StatusOK, body: `{"domain": "domain.com", "records": [], "success": "ok"}`}},
+	}
+	cli := New(
+		"token",
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithDryRun(),
+		WithoutValidation(),
+	)
+
+	response, err := cli.DNSAdd(context.Background(), "domain.com", DNSRecordType("PTR"), NewDNSParams().Content("host.domain.com"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if response.Record == nil || response.Record.Type != "PTR" || response.Record.Content != "host.domain.com" {
+		t.Errorf("unexpected record: %+v", response.Record)
+	}
+}
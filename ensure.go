@@ -0,0 +1,123 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// DNSChange tells what DNSEnsure or DNSUpsert did
+type DNSChange string
+
+const (
+	DNSCreated   DNSChange = "created"
+	DNSUpdated   DNSChange = "updated"
+	DNSUnchanged DNSChange = "unchanged"
+)
+
+// ErrAmbiguousRecord is returned by DNSUpsert when more than one record has the same type and subdomain
+var ErrAmbiguousRecord = errors.New("more than one record matches")
+
+// DNSEnsure adds a record only if there is no record with the same type, subdomain, content and priority.
+// If such record exists, it is returned with DNSUnchanged.
+func (c *Client) DNSEnsure(ctx context.Context, domain string, recordType DNSRecordType, params *DNSRequestParams) (*DNSResponse, DNSChange, error) {
+	params = params.Clone()
+	values := recordKey(recordType, params, "content", "target", "priority")
+
+	list, err := c.DNSList(ctx, domain)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, rec := range list.Records {
+		if rec.matches(values) {
+			return &DNSResponse{Domain: domain, Record: rec, Success: successOK}, DNSUnchanged, nil
+		}
+	}
+
+	r, err := c.DNSAdd(ctx, domain, recordType, params)
+	if err != nil {
+		return r, "", err
+	}
+	return r, DNSCreated, nil
+}
+
+// DNSUpsert edits the record with the same type and subdomain, or adds it if there is no such record.
+// If the record already has all values from params, it is returned with DNSUnchanged.
+// ErrAmbiguousRecord is returned if more than one record has the same type and subdomain.
+func (c *Client) DNSUpsert(ctx context.Context, domain string, recordType DNSRecordType, params *DNSRequestParams) (*DNSResponse, DNSChange, error) {
+	params = params.Clone()
+	values := recordKey(recordType, params)
+
+	list, err := c.DNSList(ctx, domain)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var found []*DNSRecord
+	for _, rec := range list.Records {
+		if rec.matches(values) {
+			found = append(found, rec)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		r, err := c.DNSAdd(ctx, domain, recordType, params)
+		if err != nil {
+			return r, "", err
+		}
+		return r, DNSCreated, nil
+	case 1:
+		rec := found[0]
+		if rec.matches(url.Values(*params)) {
+			return &DNSResponse{Domain: domain, Record: rec, Success: successOK}, DNSUnchanged, nil
+		}
+
+		r, err := c.DNSEdit(ctx, domain, rec.ID, params)
+		if err != nil {
+			return r, "", err
+		}
+		return r, DNSUpdated, nil
+	default:
+		return nil, "", fmt.Errorf("%w: %d %s records for subdomain %s in domain %s",
+			ErrAmbiguousRecord, len(found), recordType, values.Get("subdomain"), domain)
+	}
+}
+
+// recordKey returns values identifying a record: type, subdomain ("@" if not set)
+// and parameters listed in keys if they are set
+func recordKey(recordType DNSRecordType, params *DNSRequestParams, keys ...string) url.Values {
+	values := url.Values{
+		"type":      {string(recordType)},
+		"subdomain": {"@"},
+	}
+
+	p := url.Values(*params)
+	if s, ok := p["subdomain"]; ok {
+		values["subdomain"] = s
+	}
+	for _, k := range keys {
+		if v, ok := p[k]; ok {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+func (m *MultiClient) DNSEnsure(ctx context.Context, domain string, recordType DNSRecordType, params *DNSRequestParams) (*DNSResponse, DNSChange, error) {
+	cli, err := m.Client(domain)
+	if err != nil {
+		return nil, "", err
+	}
+	return cli.DNSEnsure(ctx, domain, recordType, params)
+}
+
+func (m *MultiClient) DNSUpsert(ctx context.Context, domain string, recordType DNSRecordType, params *DNSRequestParams) (*DNSResponse, DNSChange, error) {
+	cli, err := m.Client(domain)
+	if err != nil {
+		return nil, "", err
+	}
+	return cli.DNSUpsert(ctx, domain, recordType, params)
+}
@@ -0,0 +1,181 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_DNSEnsure(t *testing.T) {
+	listURL := "GET https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com"
+	addURL := "POST https://pddimp.yandex.ru/api2/admin/dns/add"
+	list := mockResponse{status: http.StatusOK, body: `{"domain": "domain.com", "records": [
+		{"record_id": 1, "type": "A", "subdomain": "www", "content": "127.0.0.1", "ttl": 300},
+		{"record_id": 2, "type": "MX", "subdomain": "@", "content": "mx.domain.com.", "priority": 10}
+	], "success": "ok"}`}
+	added := mockResponse{status: http.StatusOK, body: `{"domain": "domain.com", "record": {"record_id": 3}, "success": "ok"}`}
+
+	cases := []struct {
+		name        string
+		recordType  DNSRecordType
+		params      *DNSRequestParams
+		expChange   DNSChange
+		expID       uint32
+		expRequests []string
+	}{
+		{
+			name:        "identical record exists",
+			recordType:  DNSTypeA,
+			params:      NewDNSParams().Subdomain("WWW").Content("127.0.0.1").TTL(60),
+			expChange:   DNSUnchanged,
+			expID:       1,
+			expRequests: []string{listURL},
+		},
+		{
+			name:        "identical record at apex",
+			recordType:  DNSTypeMX,
+			params:      NewDNSParams().Content("mx.domain.com").Priority(10),
+			expChange:   DNSUnchanged,
+			expID:       2,
+			expRequests: []string{listURL},
+		},
+		{
+			name:        "different content",
+			recordType:  DNSTypeA,
+			params:      NewDNSParams().Subdomain("www").Content("127.0.0.2"),
+			expChange:   DNSCreated,
+			expID:       3,
+			expRequests: []string{listURL, addURL},
+		},
+		{
+			name:        "different priority",
+			recordType:  DNSTypeMX,
+			params:      NewDNSParams().Content("mx.domain.com").Priority(20),
+			expChange:   DNSCreated,
+			expID:       3,
+			expRequests: []string{listURL, addURL},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &sequenceTransportMock{responses: []mockResponse{list, added}}
+			cli := New("token", WithHTTPClient(&http.Client{Transport: transport}))
+
+			r, change, err := cli.DNSEnsure(context.Background(), "domain.com", tc.recordType, tc.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if change != tc.expChange || r.Record.ID != tc.expID {
+				t.Errorf("expected %s record %d, got: %s record %d", tc.expChange, tc.expID, change, r.Record.ID)
+			}
+			if !reflect.DeepEqual(tc.expRequests, transport.requests) {
+				t.Errorf("expected requests: %v, got: %v", tc.expRequests, transport.requests)
+			}
+		})
+	}
+}
+
+func TestClient_DNSUpsert(t *testing.T) {
+	listURL := "GET https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com"
+	addURL := "POST https://pddimp.yandex.ru/api2/admin/dns/add"
+	editURL := "POST https://pddimp.yandex.ru/api2/admin/dns/edit"
+	list := mockResponse{status: http.StatusOK, body: `{"domain": "domain.com", "records": [
+		{"record_id": 1, "type": "A", "subdomain": "www", "content": "127.0.0.1", "ttl": 300},
+		{"record_id": 2, "type": "TXT", "subdomain": "@", "content": "v=spf1 -all"},
+		{"record_id": 3, "type": "TXT", "subdomain": "@", "content": "google-site-verification"},
+		{"record_id": 5, "type": "SRV", "subdomain": "_sip._udp", "content": "sip.domain.com.", "ttl": 900,
+		 "priority": 10, "weight": 5, "port": 5060}
+	], "success": "ok"}`}
+	added := mockResponse{status: http.StatusOK, body: `{"domain": "domain.com", "record": {"record_id": 4}, "success": "ok"}`}
+	edited := mockResponse{status: http.StatusOK, body: `{"domain": "domain.com", "record": {"record_id": 1}, "success": "ok"}`}
+	srvEdited := mockResponse{status: http.StatusOK, body: `{"domain": "domain.com", "record": {"record_id": 5}, "success": "ok"}`}
+	srv := SRVRecord{Service: "sip", Proto: "udp", Priority: 10, Weight: 5, Port: 5060, Target: "sip.domain.com.", TTL: 900}
+	srvPort := srv
+	srvPort.Port = 5061
+
+	cases := []struct {
+		name        string
+		recordType  DNSRecordType
+		params      *DNSRequestParams
+		responses   []mockResponse
+		expErr      error
+		expChange   DNSChange
+		expID       uint32
+		expRequests []string
+	}{
+		{
+			name:        "record is up to date",
+			recordType:  DNSTypeA,
+			params:      NewDNSParams().Subdomain("www").Content("127.0.0.1").TTL(300),
+			responses:   []mockResponse{list},
+			expChange:   DNSUnchanged,
+			expID:       1,
+			expRequests: []string{listURL},
+		},
+		{
+			name:        "record is edited",
+			recordType:  DNSTypeA,
+			params:      NewDNSParams().Subdomain("www").Content("127.0.0.2"),
+			responses:   []mockResponse{list, edited},
+			expChange:   DNSUpdated,
+			expID:       1,
+			expRequests: []string{listURL, editURL},
+		},
+		{
+			name:        "SRV record is up to date",
+			recordType:  DNSTypeSRV,
+			params:      srv.Params(),
+			responses:   []mockResponse{list},
+			expChange:   DNSUnchanged,
+			expID:       5,
+			expRequests: []string{listURL},
+		},
+		{
+			name:        "only SRV port is changed",
+			recordType:  DNSTypeSRV,
+			params:      srvPort.Params(),
+			responses:   []mockResponse{list, srvEdited},
+			expChange:   DNSUpdated,
+			expID:       5,
+			expRequests: []string{listURL, editURL},
+		},
+		{
+			name:        "record is added",
+			recordType:  DNSTypeA,
+			params:      NewDNSParams().Subdomain("mail").Content("127.0.0.1"),
+			responses:   []mockResponse{list, added},
+			expChange:   DNSCreated,
+			expID:       4,
+			expRequests: []string{listURL, addURL},
+		},
+		{
+			name:        "ambiguous record",
+			recordType:  DNSTypeTXT,
+			params:      NewDNSParams().Content("v=spf1 mx -all"),
+			responses:   []mockResponse{list},
+			expErr:      ErrAmbiguousRecord,
+			expRequests: []string{listURL},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &sequenceTransportMock{responses: tc.responses}
+			cli := New("token", WithHTTPClient(&http.Client{Transport: transport}))
+
+			r, change, err := cli.DNSUpsert(context.Background(), "domain.com", tc.recordType, tc.params)
+			if !errors.Is(err, tc.expErr) {
+				t.Fatalf("expected error: %v, got: %v", tc.expErr, err)
+			}
+			if err == nil && (change != tc.expChange || r.Record.ID != tc.expID) {
+				t.Errorf("expected %s record %d, got: %s record %d", tc.expChange, tc.expID, change, r.Record.ID)
+			}
+			if !reflect.DeepEqual(tc.expRequests, transport.requests) {
+				t.Errorf("expected requests: %v, got: %v", tc.expRequests, transport.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrorCode is an error code returned by PDD in the "error" field of a response.
+// ErrorCode values can be used as targets for errors.Is.
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+// Error codes documented by PDD.
+const (
+	ErrUnknown            ErrorCode = "unknown"
+	ErrNoToken            ErrorCode = "no_token"
+	ErrNoDomain           ErrorCode = "no_domain"
+	ErrNoIP               ErrorCode = "no_ip"
+	ErrBadDomain          ErrorCode = "bad_domain"
+	ErrProhibited         ErrorCode = "prohibited"
+	ErrBadToken           ErrorCode = "bad_token"
+	ErrBadLogin           ErrorCode = "bad_login"
+	ErrBadPasswd          ErrorCode = "bad_passwd"
+	ErrNoAuth             ErrorCode = "no_auth"
+	ErrNotAllowed         ErrorCode = "not_allowed"
+	ErrBlocked            ErrorCode = "blocked"
+	ErrOccupied           ErrorCode = "occupied"
+	ErrDomainLimitReached ErrorCode = "domain_limit_reached"
+	ErrNoReply            ErrorCode = "no_reply"
+	ErrNotFound           ErrorCode = "not_found"
+)
+
+const successOK = "ok"
+
+// APIError is returned when PDD answers with a "success" value other than "ok".
+type APIError struct {
+	Section string
+	Action  string
+	Domain  string
+	Code    ErrorCode
+}
+
+func (e *APIError) Error() string {
+	if e.Domain == "" {
+		return fmt.Sprintf("pdd api error: %s/%s: %s", e.Section, e.Action, e.Code)
+	}
+	return fmt.Sprintf("pdd api error: %s/%s (domain %s): %s", e.Section, e.Action, e.Domain, e.Code)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Code
+}
+
+// apiStatus is the part of every PDD response that reports the result of a call.
+type apiStatus struct {
+	Success string `json:"success"`
+	Error   string `json:"error"`
+}
+
+func (s *apiStatus) err(op *Operation) error {
+	if s.Success == successOK {
+		return nil
+	}
+
+	return &APIError{
+		Section: op.Section,
+		Action:  op.Action,
+		Domain:  op.Domain,
+		Code:    s.code(),
+	}
+}
+
+func (s *apiStatus) code() ErrorCode {
+	if s.Error == "" {
+		return ErrUnknown
+	}
+	return ErrorCode(s.Error)
+}
+
+// maxErrorBodySize limits the part of response body kept in HTTPError
+const maxErrorBodySize = 1024
+
+// errorHeaders are response headers kept in HTTPError
+var errorHeaders = []string{
+	"Retry-After",
+	"Content-Type",
+	"Date",
+	"Server",
+	"X-Request-Id",
+}
+
+// secretParams are query parameters whose values never get into errors
+var secretParams = []string{
+	"token",
+	"pddtoken",
+	"oauth_token",
+	"password",
+	"passwd",
+}
+
+// HTTPError is returned when PDD answers with an HTTP status other than 200 OK.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	URL        string // secrets in query are redacted
+	Header     http.Header
+	Body       []byte // at most the first 1024 bytes of response body
+}
+
+func newHTTPError(req *http.Request, resp *http.Response) *HTTPError {
+	e := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     req.Method,
+		URL:        redactURL(req.URL),
+		Header:     http.Header{},
+	}
+
+	for _, h := range errorHeaders {
+		if v, ok := resp.Header[http.CanonicalHeaderKey(h)]; ok {
+			e.Header[http.CanonicalHeaderKey(h)] = v
+		}
+	}
+
+	e.Body, _ = ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	status := e.Status
+	if status == "" {
+		status = strconv.Itoa(e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: unexpected response status: %s", e.Method, e.URL, status)
+}
+
+// RetryAfter returns delay requested by server in Retry-After header
+func (e *HTTPError) RetryAfter() (time.Duration, bool) {
+	v := e.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	r := *u
+	if r.User != nil {
+		r.User = url.User(r.User.Username())
+	}
+
+	if r.RawQuery != "" {
+		q := r.Query()
+		for k := range q {
+			for _, s := range secretParams {
+				if strings.EqualFold(k, s) {
+					q.Set(k, "REDACTED")
+				}
+			}
+		}
+		r.RawQuery = q.Encode()
+	}
+
+	return r.String()
+}
+
+// IsRetryable reports whether err is a temporary failure and the call may be repeated:
+// a network error, HTTP status 429 or 5xx, or PDD error "unknown"
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == ErrUnknown
+	}
+
+	if errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+
+	return false
+}
+
+// IsAuth reports whether err is caused by missing or invalid credentials
+func IsAuth(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden
+	}
+
+	return errors.Is(err, ErrNoAuth) ||
+		errors.Is(err, ErrNoToken) ||
+		errors.Is(err, ErrBadToken) ||
+		errors.Is(err, ErrBadLogin) ||
+		errors.Is(err, ErrBadPasswd)
+}
+
+// IsNotFound reports whether err means that requested object does not exist
+func IsNotFound(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusNotFound
+	}
+
+	return errors.Is(err, ErrNotFound)
+}
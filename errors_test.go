@@ -0,0 +1,177 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	var err error = &APIError{Section: "dns", Action: "add", Domain: "domain.com", Code: ErrNoAuth}
+	err = fmt.Errorf("wrapped: %w", err)
+
+	if !errors.Is(err, ErrNoAuth) {
+		t.Errorf("expected error to match %v", ErrNoAuth)
+	}
+	if errors.Is(err, ErrBadToken) {
+		t.Errorf("expected error not to match %v", ErrBadToken)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected error to be *APIError")
+	}
+	if apiErr.Code != ErrNoAuth || apiErr.Domain != "domain.com" {
+		t.Errorf("unexpected api error: %+v", apiErr)
+	}
+}
+
+func TestAPIStatus_Err(t *testing.T) {
+	cases := []struct {
+		name   string
+		status apiStatus
+		expErr error
+	}{
+		{
+			name:   "ok",
+			status: apiStatus{Success: "ok"},
+		},
+		{
+			name:   "error with code",
+			status: apiStatus{Success: "error", Error: "bad_domain"},
+			expErr: &APIError{Section: "dns", Action: "list", Domain: "domain.com", Code: ErrBadDomain},
+		},
+		{
+			name:   "error without code",
+			status: apiStatus{Success: "error"},
+			expErr: &APIError{Section: "dns", Action: "list", Domain: "domain.com", Code: ErrUnknown},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.status.err(&Operation{Section: "dns", Action: "list", Domain: "domain.com"})
+			if fmt.Sprint(tc.expErr) != fmt.Sprint(err) {
+				t.Errorf("expected error: %v, got: %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestNewHTTPError(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com&token=secret", nil)
+	if err != nil {
+		t.Fatalf("can't create request: %s", err)
+	}
+
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Status:     "429 Too Many Requests",
+		Header: http.Header{
+			"Retry-After": {"30"},
+			"Set-Cookie":  {"session=1"},
+		},
+		Body: ioutil.NopCloser(strings.NewReader(strings.Repeat("x", 2*maxErrorBodySize))),
+	}
+
+	httpErr := newHTTPError(req, resp)
+
+	expURL := "https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com&token=REDACTED"
+	if httpErr.URL != expURL {
+		t.Errorf("expected url: %s, got: %s", expURL, httpErr.URL)
+	}
+	if !reflect.DeepEqual(httpErr.Header, http.Header{"Retry-After": {"30"}}) {
+		t.Errorf("unexpected headers: %v", httpErr.Header)
+	}
+	if len(httpErr.Body) != maxErrorBodySize {
+		t.Errorf("expected body of %d bytes, got: %d", maxErrorBodySize, len(httpErr.Body))
+	}
+	if d, ok := httpErr.RetryAfter(); !ok || d != 30*time.Second {
+		t.Errorf("expected retry after 30s, got: %v, %v", d, ok)
+	}
+
+	expErr := "GET " + expURL + ": unexpected response status: 429 Too Many Requests"
+	if httpErr.Error() != expErr {
+		t.Errorf("expected error: %s, got: %s", expErr, httpErr.Error())
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	cases := []struct {
+		name         string
+		err          error
+		expRetryable bool
+		expAuth      bool
+		expNotFound  bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:         "http 503",
+			err:          &HTTPError{StatusCode: http.StatusServiceUnavailable},
+			expRetryable: true,
+		},
+		{
+			name:         "http 429",
+			err:          &HTTPError{StatusCode: http.StatusTooManyRequests},
+			expRetryable: true,
+		},
+		{
+			name:    "http 403",
+			err:     &HTTPError{StatusCode: http.StatusForbidden},
+			expAuth: true,
+		},
+		{
+			name:        "http 404",
+			err:         &HTTPError{StatusCode: http.StatusNotFound},
+			expNotFound: true,
+		},
+		{
+			name:    "api bad_token",
+			err:     &APIError{Code: ErrBadToken},
+			expAuth: true,
+		},
+		{
+			name:         "api unknown",
+			err:          &APIError{Code: ErrUnknown},
+			expRetryable: true,
+		},
+		{
+			name:        "api not_found",
+			err:         &APIError{Code: ErrNotFound},
+			expNotFound: true,
+		},
+		{
+			name:         "connection reset",
+			err:          &url.Error{Op: "Post", URL: "https://pddimp.yandex.ru", Err: syscall.ECONNRESET},
+			expRetryable: true,
+		},
+		{
+			name: "context canceled",
+			err:  &url.Error{Op: "Post", URL: "https://pddimp.yandex.ru", Err: context.Canceled},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if IsRetryable(tc.err) != tc.expRetryable {
+				t.Errorf("expected IsRetryable: %v", tc.expRetryable)
+			}
+			if IsAuth(tc.err) != tc.expAuth {
+				t.Errorf("expected IsAuth: %v", tc.expAuth)
+			}
+			if IsNotFound(tc.err) != tc.expNotFound {
+				t.Errorf("expected IsNotFound: %v", tc.expNotFound)
+			}
+		})
+	}
+}
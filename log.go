@@ -0,0 +1,252 @@
+package yapdd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogLevel defines how much details are logged
+type LogLevel int
+
+const (
+	// LogLevelInfo logs a single event per HTTP request without bodies
+	LogLevelInfo LogLevel = iota
+	// LogLevelDebug adds request headers and bodies of request and response to events
+	LogLevelDebug
+)
+
+const redacted = "REDACTED"
+
+// secretJSONField matches string values of JSON fields with tokens and passwords
+var secretJSONField = regexp.MustCompile(`(?i)("[^"]*(?:token|passw)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// LogEvent describes a single HTTP request to PDD. Tokens and passwords are always redacted.
+type LogEvent struct {
+	Operation  string
+	Section    string
+	Action     string
+	Domain     string
+	RecordID   uint32
+	Attempt    int
+	Method     string
+	URL        string
+	Latency    time.Duration
+	StatusCode int
+	Success    string
+	ErrorCode  string
+	Err        error
+	// DryRun is set when the request was not sent because of dry-run mode
+	DryRun bool
+
+	// filled on LogLevelDebug and in dry-run mode only
+	RequestHeader http.Header
+	RequestBody   string
+	ResponseBody  string
+}
+
+// Logger receives events about requests to PDD
+type Logger interface {
+	Log(ctx context.Context, e *LogEvent)
+}
+
+// LoggerFunc is an adapter to use a function as Logger
+type LoggerFunc func(ctx context.Context, e *LogEvent)
+
+func (f LoggerFunc) Log(ctx context.Context, e *LogEvent) {
+	f(ctx, e)
+}
+
+// WithLogger makes the client report every HTTP request to l
+func WithLogger(l Logger, level LogLevel) Option {
+	return func(cli *Client) {
+		cli.logger = l
+		cli.logLevel = level
+	}
+}
+
+// NewStdLogger returns Logger writing events to l as key=value pairs
+func NewStdLogger(l *log.Logger) Logger {
+	return LoggerFunc(func(_ context.Context, e *LogEvent) {
+		l.Print(e.String())
+	})
+}
+
+func (e *LogEvent) String() string {
+	var b strings.Builder
+	field := func(k string, v interface{}) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		switch v := v.(type) {
+		case string:
+			if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+				v = strconv.Quote(v)
+			}
+			b.WriteString(v)
+		default:
+			fmt.Fprint(&b, v)
+		}
+	}
+
+	field("operation", e.Operation)
+	field("section", e.Section)
+	field("action", e.Action)
+	field("domain", e.Domain)
+	if e.RecordID != 0 {
+		field("record_id", e.RecordID)
+	}
+	field("attempt", e.Attempt)
+	field("method", e.Method)
+	field("url", e.URL)
+	field("latency", e.Latency)
+	if e.StatusCode != 0 {
+		field("status", e.StatusCode)
+	}
+	if e.Success != "" {
+		field("success", e.Success)
+	}
+	if e.ErrorCode != "" {
+		field("error_code", e.ErrorCode)
+	}
+	if e.Err != nil {
+		field("err", e.Err.Error())
+	}
+	if e.DryRun {
+		field("dry_run", true)
+	}
+	if e.RequestHeader != nil {
+		field("request_header", fmt.Sprint(e.RequestHeader))
+	}
+	if e.RequestBody != "" {
+		field("request_body", e.RequestBody)
+	}
+	if e.ResponseBody != "" {
+		field("response_body", e.ResponseBody)
+	}
+
+	return b.String()
+}
+
+func (c *Client) logExchange(ctx context.Context, op *Operation, req *http.Request, ex *exchange, err error) {
+	e := &LogEvent{
+		Operation:  op.Name,
+		Section:    op.Section,
+		Action:     op.Action,
+		Domain:     op.Domain,
+		RecordID:   recordID(op, ex.body),
+		Attempt:    attemptFrom(req.Context()),
+		Method:     req.Method,
+		URL:        redactURL(req.URL),
+		Latency:    ex.latency,
+		StatusCode: ex.statusCode,
+		Success:    ex.status.Success,
+		ErrorCode:  ex.status.Error,
+		Err:        err,
+		DryRun:     ex.dryRun,
+	}
+
+	if c.logLevel >= LogLevelDebug || ex.dryRun {
+		secrets := requestSecrets(req.Header)
+		e.RequestHeader = redactHeader(req.Header)
+		if req.Method != http.MethodGet {
+			e.RequestBody = redactString(redactForm(op.Params).Encode(), secrets)
+		}
+		e.ResponseBody = redactString(secretJSONField.ReplaceAllString(string(ex.body), `$1"`+redacted+`"`), secrets)
+	}
+
+	c.logger.Log(req.Context(), e)
+}
+
+// recordID returns ID of the record the operation works with taken from
+// parameters or from response body
+func recordID(op *Operation, body []byte) uint32 {
+	if id, err := strconv.ParseUint(op.Params.Get("record_id"), 10, 32); err == nil {
+		return uint32(id)
+	}
+
+	var r struct {
+		RecordID uint32 `json:"record_id"`
+		Record   *struct {
+			ID uint32 `json:"record_id"`
+		} `json:"record"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return 0
+	}
+	if r.Record != nil {
+		return r.Record.ID
+	}
+	return r.RecordID
+}
+
+// requestSecrets returns tokens sent in headers
+func requestSecrets(h http.Header) []string {
+	var secrets []string
+	for k, v := range h {
+		switch http.CanonicalHeaderKey(k) {
+		case "Pddtoken":
+			secrets = append(secrets, v...)
+		case "Authorization":
+			for _, a := range v {
+				if i := strings.IndexByte(a, ' '); i >= 0 {
+					a = a[i+1:]
+				}
+				secrets = append(secrets, a)
+			}
+		}
+	}
+	return secrets
+}
+
+func redactHeader(h http.Header) http.Header {
+	r := make(http.Header, len(h))
+	for k, v := range h {
+		switch http.CanonicalHeaderKey(k) {
+		case "Pddtoken":
+			r[k] = []string{redacted}
+		case "Authorization":
+			scheme := ""
+			if len(v) > 0 {
+				if i := strings.IndexByte(v[0], ' '); i >= 0 {
+					scheme = v[0][:i+1]
+				}
+			}
+			r[k] = []string{scheme + redacted}
+		default:
+			r[k] = v
+		}
+	}
+	return r
+}
+
+func redactForm(params url.Values) url.Values {
+	r := make(url.Values, len(params))
+	for k, v := range params {
+		r[k] = v
+		for _, s := range secretParams {
+			if strings.EqualFold(k, s) {
+				r[k] = []string{redacted}
+			}
+		}
+	}
+	return r
+}
+
+func redactString(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, redacted, -1)
+		}
+	}
+	return s
+}
@@ -0,0 +1,122 @@
+package yapdd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_Logger(t *testing.T) {
+	cases := []struct {
+		name     string
+		level    LogLevel
+		expEvent *LogEvent
+	}{
+		{
+			name:  "info",
+			level: LogLevelInfo,
+			expEvent: &LogEvent{
+				Operation:  "DNSEdit",
+				Section:    "dns",
+				Action:     "edit",
+				Domain:     "domain.com",
+				RecordID:   1,
+				Attempt:    1,
+				Method:     http.MethodPost,
+				URL:        "https://pddimp.yandex.ru/api2/registrar/dns/edit",
+				StatusCode: http.StatusOK,
+				Success:    "error",
+				ErrorCode:  "no_auth",
+				Err:        &APIError{Section: "dns", Action: "edit", Domain: "domain.com", Code: ErrNoAuth},
+			},
+		},
+		{
+			name:  "debug",
+			level: LogLevelDebug,
+			expEvent: &LogEvent{
+				Operation:  "DNSEdit",
+				Section:    "dns",
+				Action:     "edit",
+				Domain:     "domain.com",
+				RecordID:   1,
+				Attempt:    1,
+				Method:     http.MethodPost,
+				URL:        "https://pddimp.yandex.ru/api2/registrar/dns/edit",
+				StatusCode: http.StatusOK,
+				Success:    "error",
+				ErrorCode:  "no_auth",
+				Err:        &APIError{Section: "dns", Action: "edit", Domain: "domain.com", Code: ErrNoAuth},
+				RequestHeader: http.Header{
+					"PddToken":      {"REDACTED"},
+					"Authorization": {"OAuth REDACTED"},
+					"Content-Type":  {"application/x-www-form-urlencoded"},
+				},
+				RequestBody:  "content=REDACTED&domain=domain.com&record_id=1",
+				ResponseBody: `{"token": "REDACTED", "echo": "REDACTED", "success": "error", "error": "no_auth"}`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &httpTransportMock{
+				response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"token": "abc", "echo": "secret-token", "success": "error", "error": "no_auth"}`)),
+				},
+			}
+
+			var events []*LogEvent
+			logger := LoggerFunc(func(_ context.Context, e *LogEvent) {
+				e.Latency = 0
+				events = append(events, e)
+			})
+
+			cli := New(
+				"secret-token",
+				WithHTTPClient(&http.Client{Transport: transport}),
+				AsRegistrar("oauth-token"),
+				WithLogger(logger, tc.level),
+			)
+
+			// the token in content is stripped as well
+			cli.DNSEdit(context.Background(), "domain.com", 1, NewDNSParams().Content("oauth-token"))
+
+			if len(events) != 1 {
+				t.Fatalf("expected 1 event, got: %d", len(events))
+			}
+			if !reflect.DeepEqual(tc.expEvent, events[0]) {
+				t.Errorf("expected event:\n%+v\ngot:\n%+v", tc.expEvent, events[0])
+			}
+		})
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0))
+
+	logger.Log(context.Background(), &LogEvent{
+		Operation:  "DNSDel",
+		Section:    "dns",
+		Action:     "del",
+		Domain:     "domain.com",
+		RecordID:   1,
+		Attempt:    2,
+		Method:     http.MethodPost,
+		URL:        "https://pddimp.yandex.ru/api2/admin/dns/del",
+		StatusCode: http.StatusOK,
+		Success:    "ok",
+	})
+
+	exp := "operation=DNSDel section=dns action=del domain=domain.com record_id=1 attempt=2 method=POST " +
+		"url=https://pddimp.yandex.ru/api2/admin/dns/del latency=0s status=200 success=ok\n"
+	if buf.String() != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, buf.String())
+	}
+}
@@ -0,0 +1,279 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcomes of calls reported to MetricsRecorder
+const (
+	OutcomeOK             = "ok"
+	OutcomeAPIError       = "api_error"
+	OutcomeHTTPError      = "http_error"
+	OutcomeTransportError = "transport_error"
+	OutcomeCanceled       = "canceled"
+	OutcomeError          = "error"
+)
+
+// MetricsRecorder receives measurements of client calls
+type MetricsRecorder interface {
+	// ObserveCall is called once per logical call, retries included
+	ObserveCall(section, action, outcome string, latency time.Duration)
+	// IncRetry is called before every repeated attempt of a call
+	IncRetry(section, action string)
+	// ObserveRateLimitWait is called when a request waited for the rate limiter
+	ObserveRateLimitWait(section, action string, wait time.Duration)
+	// IncAPIError is called for every error code returned by PDD
+	IncAPIError(section, action string, code ErrorCode)
+}
+
+// WithMetrics makes the client report measurements to m
+func WithMetrics(m MetricsRecorder) Option {
+	return func(cli *Client) {
+		cli.metrics = m
+	}
+}
+
+// outcome classifies the result of a call
+func outcome(err error) string {
+	if err == nil {
+		return OutcomeOK
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return OutcomeCanceled
+	}
+
+	var (
+		apiErr  *APIError
+		httpErr *HTTPError
+		urlErr  *url.Error
+	)
+	switch {
+	case errors.As(err, &apiErr):
+		return OutcomeAPIError
+	case errors.As(err, &httpErr):
+		return OutcomeHTTPError
+	case errors.As(err, &urlErr):
+		return OutcomeTransportError
+	}
+
+	return OutcomeError
+}
+
+// DefaultLatencyBuckets are upper bounds of latency histogram buckets in seconds
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MemoryMetrics is MetricsRecorder keeping measurements in memory.
+// It renders them in Prometheus text exposition format and can be mounted as /metrics handler.
+type MemoryMetrics struct {
+	mu       sync.Mutex
+	buckets  []float64
+	calls    map[[3]string]uint64
+	latency  map[[2]string]*histogram
+	retries  map[[2]string]uint64
+	waits    map[[2]string]*histogram
+	apiError map[[3]string]uint64
+}
+
+type histogram struct {
+	counts []uint64 // cumulative count for every bucket
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+	for i, b := range buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// NewMemoryMetrics returns MemoryMetrics with histogram buckets in seconds,
+// DefaultLatencyBuckets are used if none are given
+func NewMemoryMetrics(buckets ...float64) *MemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &MemoryMetrics{
+		buckets:  buckets,
+		calls:    map[[3]string]uint64{},
+		latency:  map[[2]string]*histogram{},
+		retries:  map[[2]string]uint64{},
+		waits:    map[[2]string]*histogram{},
+		apiError: map[[3]string]uint64{},
+	}
+}
+
+func (m *MemoryMetrics) ObserveCall(section, action, outcome string, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls[[3]string{section, action, outcome}]++
+	m.histogram(m.latency, section, action).observe(m.buckets, latency.Seconds())
+}
+
+func (m *MemoryMetrics) IncRetry(section, action string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries[[2]string{section, action}]++
+}
+
+func (m *MemoryMetrics) ObserveRateLimitWait(section, action string, wait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.histogram(m.waits, section, action).observe(m.buckets, wait.Seconds())
+}
+
+func (m *MemoryMetrics) IncAPIError(section, action string, code ErrorCode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.apiError[[3]string{section, action, string(code)}]++
+}
+
+func (m *MemoryMetrics) histogram(hs map[[2]string]*histogram, section, action string) *histogram {
+	k := [2]string{section, action}
+	h, ok := hs[k]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		hs[k] = h
+	}
+	return h
+}
+
+// WritePrometheus writes all measurements to w in Prometheus text exposition format
+func (m *MemoryMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pw := &promWriter{w: w}
+
+	pw.header("yapdd_calls_total", "counter", "Number of calls of PDD API.")
+	for _, k := range sortedKeys3(m.calls) {
+		pw.sample("yapdd_calls_total", labels("section", k[0], "action", k[1], "outcome", k[2]), float64(m.calls[k]))
+	}
+
+	pw.histogram("yapdd_call_duration_seconds", "Duration of calls of PDD API including retries.", m.buckets, m.latency)
+
+	pw.header("yapdd_retries_total", "counter", "Number of repeated attempts of calls.")
+	for _, k := range sortedKeys2(m.retries) {
+		pw.sample("yapdd_retries_total", labels("section", k[0], "action", k[1]), float64(m.retries[k]))
+	}
+
+	pw.histogram("yapdd_rate_limit_wait_seconds", "Time requests waited for the rate limiter.", m.buckets, m.waits)
+
+	pw.header("yapdd_api_errors_total", "counter", "Number of errors returned by PDD API by code.")
+	for _, k := range sortedKeys3(m.apiError) {
+		pw.sample("yapdd_api_errors_total", labels("section", k[0], "action", k[1], "code", k[2]), float64(m.apiError[k]))
+	}
+
+	return pw.err
+}
+
+// ServeHTTP serves measurements in Prometheus text exposition format
+func (m *MemoryMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
+
+// promWriter writes Prometheus text format remembering the first error
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (pw *promWriter) printf(format string, args ...interface{}) {
+	if pw.err == nil {
+		_, pw.err = fmt.Fprintf(pw.w, format, args...)
+	}
+}
+
+func (pw *promWriter) header(name, typ, help string) {
+	pw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (pw *promWriter) sample(name, labels string, v float64) {
+	pw.printf("%s{%s} %s\n", name, labels, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func (pw *promWriter) histogram(name, help string, buckets []float64, hs map[[2]string]*histogram) {
+	pw.header(name, "histogram", help)
+	for _, k := range sortedHistogramKeys(hs) {
+		h := hs[k]
+		l := labels("section", k[0], "action", k[1])
+		for i, b := range buckets {
+			pw.sample(name+"_bucket", l+`,le="`+strconv.FormatFloat(b, 'g', -1, 64)+`"`, float64(h.counts[i]))
+		}
+		pw.sample(name+"_bucket", l+`,le="+Inf"`, float64(h.count))
+		pw.sample(name+"_sum", l, h.sum)
+		pw.sample(name+"_count", l, float64(h.count))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func labels(kv ...string) string {
+	parts := make([]string, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		parts = append(parts, kv[i]+`="`+labelEscaper.Replace(kv[i+1])+`"`)
+	}
+	return strings.Join(parts, ",")
+}
+
+func sortedKeys2(m map[[2]string]uint64) [][2]string {
+	keys := make([][2]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortKeys2(keys)
+	return keys
+}
+
+func sortedHistogramKeys(m map[[2]string]*histogram) [][2]string {
+	keys := make([][2]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortKeys2(keys)
+	return keys
+}
+
+func sortKeys2(keys [][2]string) {
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1]
+	})
+}
+
+func sortedKeys3(m map[[3]string]uint64) [][3]string {
+	keys := make([][3]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		for n := 0; n < 3; n++ {
+			if keys[i][n] != keys[j][n] {
+				return keys[i][n] < keys[j][n]
+			}
+		}
+		return false
+	})
+	return keys
+}
@@ -0,0 +1,103 @@
+package yapdd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_Metrics(t *testing.T) {
+	transport := &sequenceTransportMock{
+		responses: []mockResponse{
+			{status: http.StatusServiceUnavailable},
+			{status: http.StatusOK, body: `{"success": "error", "error": "no_auth"}`},
+			{status: http.StatusOK, body: `{"success": "ok"}`},
+		},
+	}
+
+	m := NewMemoryMetrics(0.1, 1)
+	cli := New(
+		"token",
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithRetry(RetryPolicy{MinBackoff: time.Millisecond}),
+		WithMetrics(m),
+	)
+
+	cli.DNSList(context.Background(), "domain.com")
+	cli.DNSDel(context.Background(), "domain.com", 1)
+
+	// latencies are not deterministic
+	for _, h := range m.latency {
+		h.counts = []uint64{h.count, h.count}
+		h.sum = 0
+	}
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := `# HELP yapdd_calls_total Number of calls of PDD API.
+# TYPE yapdd_calls_total counter
+yapdd_calls_total{section="dns",action="del",outcome="ok"} 1
+yapdd_calls_total{section="dns",action="list",outcome="api_error"} 1
+# HELP yapdd_call_duration_seconds Duration of calls of PDD API including retries.
+# TYPE yapdd_call_duration_seconds histogram
+yapdd_call_duration_seconds_bucket{section="dns",action="del",le="0.1"} 1
+yapdd_call_duration_seconds_bucket{section="dns",action="del",le="1"} 1
+yapdd_call_duration_seconds_bucket{section="dns",action="del",le="+Inf"} 1
+yapdd_call_duration_seconds_sum{section="dns",action="del"} 0
+yapdd_call_duration_seconds_count{section="dns",action="del"} 1
+yapdd_call_duration_seconds_bucket{section="dns",action="list",le="0.1"} 1
+yapdd_call_duration_seconds_bucket{section="dns",action="list",le="1"} 1
+yapdd_call_duration_seconds_bucket{section="dns",action="list",le="+Inf"} 1
+yapdd_call_duration_seconds_sum{section="dns",action="list"} 0
+yapdd_call_duration_seconds_count{section="dns",action="list"} 1
+# HELP yapdd_retries_total Number of repeated attempts of calls.
+# TYPE yapdd_retries_total counter
+yapdd_retries_total{section="dns",action="list"} 1
+# HELP yapdd_rate_limit_wait_seconds Time requests waited for the rate limiter.
+# TYPE yapdd_rate_limit_wait_seconds histogram
+# HELP yapdd_api_errors_total Number of errors returned by PDD API by code.
+# TYPE yapdd_api_errors_total counter
+yapdd_api_errors_total{section="dns",action="list",code="no_auth"} 1
+`
+	if buf.String() != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, buf.String())
+	}
+}
+
+func TestMemoryMetrics_Histogram(t *testing.T) {
+	m := NewMemoryMetrics(1, 0.1)
+	m.ObserveRateLimitWait("dns", "add", 50*time.Millisecond)
+	m.ObserveRateLimitWait("dns", "add", 500*time.Millisecond)
+	m.ObserveRateLimitWait("dns", "add", 2*time.Second)
+
+	h := m.waits[[2]string{"dns", "add"}]
+	if h.counts[0] != 1 || h.counts[1] != 2 || h.count != 3 {
+		t.Errorf("unexpected histogram: %+v", h)
+	}
+	if h.sum != 2.55 {
+		t.Errorf("expected sum 2.55, got: %v", h.sum)
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	cases := []struct {
+		err error
+		exp string
+	}{
+		{nil, OutcomeOK},
+		{&APIError{Code: ErrNoAuth}, OutcomeAPIError},
+		{&HTTPError{StatusCode: http.StatusBadGateway}, OutcomeHTTPError},
+		{context.Canceled, OutcomeCanceled},
+	}
+
+	for _, tc := range cases {
+		if o := outcome(tc.err); o != tc.exp {
+			t.Errorf("%v: expected outcome: %s, got: %s", tc.err, tc.exp, o)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package yapdd
+
+import (
+	"context"
+)
+
+// Handler performs an operation and decodes its result into result
+type Handler func(ctx context.Context, op *Operation, result interface{}) error
+
+// Middleware wraps a handler. It may change the operation, e.g. add headers,
+// before calling next, and inspect the decoded result and the error after.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middlewares to the chain every call of the client runs through.
+// The first middleware is the outermost one. The chain is run once per logical call,
+// retries happen inside it.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(cli *Client) {
+		cli.middlewares = append(cli.middlewares, mw...)
+	}
+}
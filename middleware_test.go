@@ -0,0 +1,88 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_Middleware(t *testing.T) {
+	transport := &httpTransportMock{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"domain": "domain.com", "record_id": 1, "success": "ok"}`)),
+		},
+	}
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, op *Operation, result interface{}) error {
+				calls = append(calls, name+" before "+op.Name+" "+op.Section+"/"+op.Action+" "+op.Domain+" "+op.Params.Encode())
+				err := next(ctx, op, result)
+				calls = append(calls, name+" after "+result.(*DNSResponse).Success)
+				return err
+			}
+		}
+	}
+	correlation := func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation, result interface{}) error {
+			op.Header.Set("X-Correlation-Id", "42")
+			return next(ctx, op, result)
+		}
+	}
+
+	cli := New(
+		"token",
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithMiddleware(record("first"), record("second")),
+		WithMiddleware(correlation),
+	)
+
+	if _, err := cli.DNSDel(context.Background(), "domain.com", 1); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expCalls := []string{
+		"first before DNSDel dns/del domain.com domain=domain.com&record_id=1",
+		"second before DNSDel dns/del domain.com domain=domain.com&record_id=1",
+		"second after ok",
+		"first after ok",
+	}
+	if !reflect.DeepEqual(expCalls, calls) {
+		t.Errorf("expected calls:\n%v\ngot:\n%v", expCalls, calls)
+	}
+
+	if v := transport.request.Header.Get("X-Correlation-Id"); v != "42" {
+		t.Errorf("expected correlation header: 42, got: %q", v)
+	}
+	if v := transport.request.Header["PddToken"]; !reflect.DeepEqual(v, []string{"token"}) {
+		t.Errorf("expected PddToken header: token, got: %q", v)
+	}
+}
+
+func TestClient_MiddlewareShortCircuit(t *testing.T) {
+	transport := &httpTransportMock{}
+	fault := errors.New("injected fault")
+
+	cli := New(
+		"token",
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithMiddleware(func(next Handler) Handler {
+			return func(ctx context.Context, op *Operation, result interface{}) error {
+				return fault
+			}
+		}),
+	)
+
+	if _, err := cli.DNSList(context.Background(), "domain.com"); err != fault {
+		t.Errorf("expected error: %v, got: %v", fault, err)
+	}
+	if transport.request != nil {
+		t.Errorf("expected no request to be sent")
+	}
+}
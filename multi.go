@@ -0,0 +1,201 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// discoverPageSize is the number of domains requested per page by MultiClient.Discover
+const discoverPageSize = 100
+
+// ErrNoRoute is returned by MultiClient for a domain without credentials
+var ErrNoRoute = errors.New("no credentials for domain")
+
+// Credentials are tokens of a PDD account. An account with OAuthToken is used as registrar.
+type Credentials struct {
+	PddToken   string
+	OAuthToken string
+}
+
+// MultiClient routes calls to clients of different accounts by domain
+type MultiClient struct {
+	opts []Option
+
+	mu       sync.RWMutex
+	clients  map[Credentials]*Client
+	exact    map[string]*Client
+	suffixes []suffixRoute // sorted from the longest suffix
+}
+
+type suffixRoute struct {
+	suffix string // with leading dot, empty for "*"
+	client *Client
+}
+
+// NewMultiClient returns a client routing calls by routes. A key of routes is either a domain,
+// or a wildcard rule "*.domain.com" matching all domains ending with ".domain.com", or "*" matching any domain.
+// An exact domain has priority over rules, a longer rule has priority over a shorter one.
+// Options are applied to every underlying client.
+func NewMultiClient(routes map[string]Credentials, opts ...Option) *MultiClient {
+	m := &MultiClient{
+		opts:    opts,
+		clients: map[Credentials]*Client{},
+		exact:   map[string]*Client{},
+	}
+
+	for pattern, cr := range routes {
+		m.addRoute(pattern, cr)
+	}
+
+	return m
+}
+
+// AddRoute adds or replaces a route
+func (m *MultiClient) AddRoute(pattern string, cr Credentials) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.addRoute(pattern, cr)
+}
+
+func (m *MultiClient) addRoute(pattern string, cr Credentials) {
+	cli := m.client(cr)
+	pattern = normalizeDomain(pattern)
+
+	if pattern != "*" && !strings.HasPrefix(pattern, "*.") {
+		m.exact[pattern] = cli
+		return
+	}
+
+	suffix := strings.TrimPrefix(pattern, "*")
+	for i, r := range m.suffixes {
+		if r.suffix == suffix {
+			m.suffixes[i].client = cli
+			return
+		}
+	}
+
+	m.suffixes = append(m.suffixes, suffixRoute{suffix: suffix, client: cli})
+	sort.SliceStable(m.suffixes, func(i, j int) bool {
+		return len(m.suffixes[i].suffix) > len(m.suffixes[j].suffix)
+	})
+}
+
+// client returns the client for credentials creating it if necessary
+func (m *MultiClient) client(cr Credentials) *Client {
+	if cli, ok := m.clients[cr]; ok {
+		return cli
+	}
+
+	opts := append([]Option(nil), m.opts...)
+	if cr.OAuthToken != "" {
+		opts = append(opts, AsRegistrar(cr.OAuthToken))
+	}
+
+	cli := New(cr.PddToken, opts...)
+	m.clients[cr] = cli
+	return cli
+}
+
+// Client returns the client used for domain
+func (m *MultiClient) Client(domain string) (*Client, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	domain = normalizeDomain(domain)
+	if cli, ok := m.exact[domain]; ok {
+		return cli, nil
+	}
+
+	for _, r := range m.suffixes {
+		if strings.HasSuffix(domain, r.suffix) {
+			return r.client, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w %s", ErrNoRoute, domain)
+}
+
+// Discover lists domains of every account and adds routes for them.
+// Domains which already have an exact route keep it.
+func (m *MultiClient) Discover(ctx context.Context, accounts ...Credentials) error {
+	for _, cr := range accounts {
+		m.mu.Lock()
+		cli := m.client(cr)
+		m.mu.Unlock()
+
+		domains, err := listAllDomains(ctx, cli)
+		if err != nil {
+			return fmt.Errorf("can't list domains of account: %w", err)
+		}
+
+		m.mu.Lock()
+		for _, d := range domains {
+			d = normalizeDomain(d)
+			if _, ok := m.exact[d]; !ok {
+				m.exact[d] = cli
+			}
+		}
+		m.mu.Unlock()
+	}
+
+	return nil
+}
+
+func listAllDomains(ctx context.Context, cli *Client) ([]string, error) {
+	var domains []string
+	for page := 1; ; page++ {
+		r, err := cli.DomainList(ctx, page, discoverPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range r.Domains {
+			domains = append(domains, d.Name)
+		}
+
+		if len(r.Domains) < discoverPageSize || r.Found > 0 && len(domains) >= r.Found {
+			return domains, nil
+		}
+	}
+}
+
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+}
+
+func (m *MultiClient) DNSAdd(ctx context.Context, domain string, recordType DNSRecordType, params *DNSRequestParams) (*DNSResponse, error) {
+	cli, err := m.Client(domain)
+	if err != nil {
+		return nil, err
+	}
+	return cli.DNSAdd(ctx, domain, recordType, params)
+}
+
+func (m *MultiClient) DNSList(ctx context.Context, domain string) (*DNSListResponse, error) {
+	cli, err := m.Client(domain)
+	if err != nil {
+		return nil, err
+	}
+	return cli.DNSList(ctx, domain)
+}
+
+func (m *MultiClient) DNSEdit(ctx context.Context, domain string, recordID uint32, params *DNSRequestParams) (*DNSResponse, error) {
+	cli, err := m.Client(domain)
+	if err != nil {
+		return nil, err
+	}
+	return cli.DNSEdit(ctx, domain, recordID, params)
+}
+
+func (m *MultiClient) DNSDel(ctx context.Context, domain string, recordID uint32) (*DNSResponse, error) {
+	cli, err := m.Client(domain)
+	if err != nil {
+		return nil, err
+	}
+	return cli.DNSDel(ctx, domain, recordID)
+}
@@ -0,0 +1,128 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMultiClient_Client(t *testing.T) {
+	m := NewMultiClient(map[string]Credentials{
+		"domain.com":       {PddToken: "exact"},
+		"*.domain.com":     {PddToken: "wildcard"},
+		"*.sub.domain.com": {PddToken: "longer"},
+		"registrar.com":    {PddToken: "registrar", OAuthToken: "oauth"},
+	})
+
+	cases := []struct {
+		domain   string
+		expToken string
+		expErr   error
+	}{
+		{domain: "domain.com", expToken: "exact"},
+		{domain: "Domain.COM.", expToken: "exact"},
+		{domain: "shop.domain.com", expToken: "wildcard"},
+		{domain: "a.sub.domain.com", expToken: "longer"},
+		{domain: "registrar.com", expToken: "registrar"},
+		{domain: "other.com", expErr: ErrNoRoute},
+		{domain: "notdomain.com", expErr: ErrNoRoute},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.domain, func(t *testing.T) {
+			cli, err := m.Client(tc.domain)
+			if !errors.Is(err, tc.expErr) {
+				t.Fatalf("expected error: %v, got: %v", tc.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if token, _ := cli.pddToken.Token(context.Background()); token != tc.expToken {
+				t.Errorf("expected client with token: %s, got: %s", tc.expToken, token)
+			}
+		})
+	}
+
+	if cli, _ := m.Client("registrar.com"); cli.clientType != clientTypeRegistrar {
+		t.Errorf("expected registrar client")
+	}
+
+	m.AddRoute("*", Credentials{PddToken: "default"})
+	if cli, err := m.Client("other.com"); err != nil || cli != m.clients[Credentials{PddToken: "default"}] {
+		t.Errorf("expected default client, got: %v", err)
+	}
+}
+
+// accountsTransportMock answers domain/domains and dns/list requests for accounts by PddToken
+type accountsTransportMock struct {
+	domains map[string][]string
+}
+
+func (m *accountsTransportMock) RoundTrip(r *http.Request) (*http.Response, error) {
+	token := r.Header["PddToken"][0]
+
+	var body string
+	if strings.Contains(r.URL.Path, "/domain/domains") {
+		var names []string
+		for _, d := range m.domains[token] {
+			names = append(names, fmt.Sprintf(`{"name": %q}`, d))
+		}
+		body = fmt.Sprintf(`{"domains": [%s], "found": %d, "success": "ok"}`, strings.Join(names, ","), len(names))
+	} else {
+		body = fmt.Sprintf(`{"domain": %q, "records": [{"content": %q}], "success": "ok"}`, r.URL.Query().Get("domain"), token)
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestMultiClient_Discover(t *testing.T) {
+	transport := &accountsTransportMock{
+		domains: map[string][]string{
+			"first":  {"first.com", "shared.com"},
+			"second": {"second.com", "shared.com", "explicit.com"},
+		},
+	}
+
+	m := NewMultiClient(
+		map[string]Credentials{"explicit.com": {PddToken: "explicit"}},
+		WithHTTPClient(&http.Client{Transport: transport}),
+	)
+
+	err := m.Discover(context.Background(), Credentials{PddToken: "first"}, Credentials{PddToken: "second"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for domain, expToken := range map[string]string{
+		"first.com":    "first",
+		"second.com":   "second",
+		"shared.com":   "first",
+		"explicit.com": "explicit",
+	} {
+		r, err := m.DNSList(context.Background(), domain)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", domain, err)
+			continue
+		}
+		if r.Records[0].Content != expToken {
+			t.Errorf("%s: expected request with token: %s, got: %s", domain, expToken, r.Records[0].Content)
+		}
+	}
+
+	if _, err := m.DNSDel(context.Background(), "unknown.com", 1); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("expected error: %v, got: %v", ErrNoRoute, err)
+	}
+	if _, err := m.DNSAddRecord(context.Background(), "unknown.com", TXTRecord{Text: "text"}); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("expected error: %v, got: %v", ErrNoRoute, err)
+	}
+	if _, err := m.DNSEditRecord(context.Background(), "first.com", 1, TXTRecord{Text: "text"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,226 @@
+package yapdd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// minRateFactor limits how much the rate is reduced after throttling errors
+	minRateFactor = 1.0 / 16
+	// rateRecoverySteps is the number of successful calls needed to restore the rate after throttling
+	rateRecoverySteps = 10
+)
+
+// limiterIdleTimeout is the time after which an unused limiter is removed
+const limiterIdleTimeout = 10 * time.Minute
+
+// limiters holds rate limiters shared by clients with the same key.
+// Keys are hashes of PDD tokens or keys set by WithRateLimitKey, tokens are never kept.
+var limiters = struct {
+	sync.Mutex
+	m     map[string]*rateLimiter
+	swept time.Time
+}{m: map[string]*rateLimiter{}}
+
+// WithRateLimit limits the client to rps requests per second with bursts of burst requests.
+// Every request waits for the limiter before being sent or fails when ctx is done.
+// The limiter is shared by all clients using the same PDD token or the same key set by WithRateLimitKey.
+// If they are created with different settings, the lowest rate and burst are used.
+// After throttling (HTTP status 429) the rate is reduced and then restored gradually with successful requests.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(cli *Client) {
+		if rps <= 0 {
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		cli.rateLimit = &rateLimit{rps: rps, burst: burst}
+	}
+}
+
+// WithRateLimitKey makes clients with the same key share the limiter of WithRateLimit instead of
+// clients with the same PDD token. With rotated tokens the limiter and its throttling state are kept.
+func WithRateLimitKey(key string) Option {
+	return func(cli *Client) {
+		cli.rateLimitKey = key
+	}
+}
+
+type rateLimit struct {
+	rps   float64
+	burst int
+}
+
+// limiterKey returns the key of the limiter shared by clients using token
+func (c *Client) limiterKey(token string) string {
+	if c.rateLimitKey != "" {
+		return "key:" + c.rateLimitKey
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:])
+}
+
+// limiterFor returns the limiter with key and removes limiters which have not been used for a while
+func limiterFor(key string, rl *rateLimit) *rateLimiter {
+	limiters.Lock()
+	defer limiters.Unlock()
+
+	now := time.Now()
+	if now.Sub(limiters.swept) >= limiterIdleTimeout {
+		for k, l := range limiters.m {
+			if l.idle(now) >= limiterIdleTimeout {
+				delete(limiters.m, k)
+			}
+		}
+		limiters.swept = now
+	}
+
+	l, ok := limiters.m[key]
+	if !ok {
+		l = newRateLimiter(rl.rps, rl.burst)
+		limiters.m[key] = l
+	} else {
+		l.restrict(rl.rps, rl.burst)
+	}
+	return l
+}
+
+// rateLimiter is a token bucket with adaptive rate
+type rateLimiter struct {
+	mu      sync.Mutex
+	maxRate float64
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	used    time.Time // time of the last reservation or creation
+	now     func() time.Time
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		maxRate: rps,
+		rate:    rps,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+		used:    time.Now(),
+		now:     time.Now,
+	}
+}
+
+// restrict lowers the rate and burst of a shared limiter to the settings of another client
+func (l *rateLimiter) restrict(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rps < l.maxRate {
+		l.maxRate = rps
+		if l.rate > rps {
+			l.rate = rps
+		}
+	}
+	if b := float64(burst); b < l.burst {
+		l.burst = b
+		if l.tokens > b {
+			l.tokens = b
+		}
+	}
+}
+
+// idle returns the time since the limiter was used
+func (l *rateLimiter) idle(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return now.Sub(l.used)
+}
+
+// reserve takes a token and returns the delay after which it may be used
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.used = time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a token which was reserved but not used
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// wait blocks until a request may be sent and returns the time spent waiting
+func (l *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	d := l.reserve()
+	if d == 0 {
+		return 0, nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		l.cancel()
+		return 0, context.DeadlineExceeded
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return d, nil
+	case <-ctx.Done():
+		l.cancel()
+		return 0, ctx.Err()
+	}
+}
+
+// update adapts the rate to the result of a request
+func (l *rateLimiter) update(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if isThrottled(err) {
+		l.rate /= 2
+		if min := l.maxRate * minRateFactor; l.rate < min {
+			l.rate = min
+		}
+		return
+	}
+
+	if err == nil && l.rate < l.maxRate {
+		l.rate += l.maxRate / rateRecoverySteps
+		if l.rate > l.maxRate {
+			l.rate = l.maxRate
+		}
+	}
+}
+
+// isThrottled reports whether err means that PDD rejected the request because of the rate
+func isThrottled(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests
+}
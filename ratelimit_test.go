@@ -0,0 +1,114 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(2, 2)
+	l.now = func() time.Time { return now }
+
+	for i, exp := range []time.Duration{0, 0, 500 * time.Millisecond, time.Second} {
+		if d := l.reserve(); d != exp {
+			t.Errorf("reservation %d: expected delay: %v, got: %v", i, exp, d)
+		}
+	}
+
+	now = now.Add(2 * time.Second)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("expected no delay after tokens were refilled, got: %v", d)
+	}
+}
+
+func TestRateLimiter_Update(t *testing.T) {
+	l := newRateLimiter(10, 1)
+	throttled := &HTTPError{StatusCode: http.StatusTooManyRequests}
+
+	l.update(throttled)
+	if l.rate != 5 {
+		t.Errorf("expected rate 5 after throttling, got: %v", l.rate)
+	}
+
+	for i := 0; i < 10; i++ {
+		l.update(throttled)
+	}
+	if l.rate != 10*minRateFactor {
+		t.Errorf("expected minimal rate %v, got: %v", 10*minRateFactor, l.rate)
+	}
+
+	l.update(errors.New("fail"))
+	if l.rate != 10*minRateFactor {
+		t.Errorf("expected rate not changed by other errors, got: %v", l.rate)
+	}
+
+	for i := 0; i < rateRecoverySteps; i++ {
+		l.update(nil)
+	}
+	if l.rate != 10 {
+		t.Errorf("expected rate restored to 10, got: %v", l.rate)
+	}
+}
+
+func TestRateLimiter_WaitCanceled(t *testing.T) {
+	l := newRateLimiter(1, 1)
+	if _, err := l.wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected error: %v, got: %v", context.DeadlineExceeded, err)
+	}
+	if l.tokens < -0.01 {
+		t.Errorf("expected reserved token to be returned, got tokens: %v", l.tokens)
+	}
+}
+
+func TestLimiterFor_SharedByKey(t *testing.T) {
+	rl := &rateLimit{rps: 1, burst: 1}
+	cli := New("shared-token")
+	keyed := New("", WithRateLimitKey("account"))
+
+	key := cli.limiterKey("shared-token")
+	if strings.Contains(key, "shared-token") {
+		t.Errorf("expected the token not to be kept in the key, got: %s", key)
+	}
+	if limiterFor(key, rl) != limiterFor(cli.limiterKey("shared-token"), rl) {
+		t.Errorf("expected the same limiter for the same token")
+	}
+	if limiterFor(key, rl) == limiterFor(cli.limiterKey("other-token"), rl) {
+		t.Errorf("expected different limiters for different tokens")
+	}
+	if limiterFor(keyed.limiterKey("old-token"), rl) != limiterFor(keyed.limiterKey("new-token"), rl) {
+		t.Errorf("expected the same limiter for rotated tokens with the same key")
+	}
+}
+
+func TestLimiterFor_Settings(t *testing.T) {
+	l := limiterFor("key:settings", &rateLimit{rps: 10, burst: 5})
+	limiterFor("key:settings", &rateLimit{rps: 20, burst: 2})
+	if l.maxRate != 10 || l.rate != 10 || l.burst != 2 || l.tokens != 2 {
+		t.Errorf("expected the lowest rate 10 and burst 2, got: %v, %v", l.maxRate, l.burst)
+	}
+}
+
+func TestLimiterFor_Idle(t *testing.T) {
+	l := limiterFor("key:idle", &rateLimit{rps: 1, burst: 1})
+	l.used = time.Now().Add(-limiterIdleTimeout)
+
+	limiters.Lock()
+	limiters.swept = time.Time{}
+	limiters.Unlock()
+
+	if limiterFor("key:idle", &rateLimit{rps: 1, burst: 1}) == l {
+		t.Errorf("expected an idle limiter to be removed")
+	}
+}
@@ -0,0 +1,237 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Record is a typed value of a DNS record which encodes to parameters of DNSAdd and DNSEdit
+type Record interface {
+	Type() DNSRecordType
+	Params() *DNSRequestParams
+}
+
+type ARecord struct {
+	Subdomain string
+	IP        net.IP
+	TTL       uint32 // PDD default is used if zero
+}
+
+func (r ARecord) Type() DNSRecordType { return DNSTypeA }
+
+func (r ARecord) Params() *DNSRequestParams {
+	return recordParams(r.Subdomain, r.TTL).Content(r.IP.String())
+}
+
+type AAAARecord struct {
+	Subdomain string
+	IP        net.IP
+	TTL       uint32
+}
+
+func (r AAAARecord) Type() DNSRecordType { return DNSTypeAAAA }
+
+func (r AAAARecord) Params() *DNSRequestParams {
+	return recordParams(r.Subdomain, r.TTL).Content(r.IP.String())
+}
+
+type CNAMERecord struct {
+	Subdomain string
+	Target    string
+	TTL       uint32
+}
+
+func (r CNAMERecord) Type() DNSRecordType { return DNSTypeCNAME }
+
+func (r CNAMERecord) Params() *DNSRequestParams {
+	return recordParams(r.Subdomain, r.TTL).Content(r.Target)
+}
+
+type NSRecord struct {
+	Subdomain  string
+	NameServer string
+	TTL        uint32
+}
+
+func (r NSRecord) Type() DNSRecordType { return DNSTypeNS }
+
+func (r NSRecord) Params() *DNSRequestParams {
+	return recordParams(r.Subdomain, r.TTL).Content(r.NameServer)
+}
+
+type MXRecord struct {
+	Subdomain string
+	Priority  uint16
+	Exchange  string
+	TTL       uint32
+}
+
+func (r MXRecord) Type() DNSRecordType { return DNSTypeMX }
+
+func (r MXRecord) Params() *DNSRequestParams {
+	return recordParams(r.Subdomain, r.TTL).Content(r.Exchange).Priority(r.Priority)
+}
+
+type TXTRecord struct {
+	Subdomain string
+	Text      string
+	TTL       uint32
+}
+
+func (r TXTRecord) Type() DNSRecordType { return DNSTypeTXT }
+
+func (r TXTRecord) Params() *DNSRequestParams {
+	return recordParams(r.Subdomain, r.TTL).Content(r.Text)
+}
+
+// SRVRecord is published at subdomain "_service._proto" or "_service._proto.subdomain"
+type SRVRecord struct {
+	Service   string // e.g. "xmpp-client", a leading underscore is optional
+	Proto     string // e.g. "tcp", a leading underscore is optional
+	Subdomain string // empty or "@" for the domain itself
+	Priority  uint16
+	Weight    uint16
+	Port      uint16
+	Target    string
+	TTL       uint32
+}
+
+func (r SRVRecord) Type() DNSRecordType { return DNSTypeSRV }
+
+func (r SRVRecord) Params() *DNSRequestParams {
+	subdomain := "_" + strings.TrimPrefix(r.Service, "_") + "._" + strings.TrimPrefix(r.Proto, "_")
+	if r.Subdomain != "" && r.Subdomain != "@" {
+		subdomain += "." + r.Subdomain
+	}
+	return recordParams(subdomain, r.TTL).
+		Priority(r.Priority).
+		Weight(r.Weight).
+		Port(r.Port).
+		Target(r.Target)
+}
+
+// SOARecord contains the editable fields of SOA record of the domain
+type SOARecord struct {
+	AdminMail string
+	Refresh   uint32
+	Retry     uint32
+	Expire    uint32
+	NegCache  uint32
+	TTL       uint32
+}
+
+func (r SOARecord) Type() DNSRecordType { return DNSTypeSOA }
+
+func (r SOARecord) Params() *DNSRequestParams {
+	p := recordParams("", r.TTL)
+	if r.AdminMail != "" {
+		p.AdminMail(r.AdminMail)
+	}
+	if r.Refresh > 0 {
+		p.Refresh(r.Refresh)
+	}
+	if r.Retry > 0 {
+		p.SetRetry(r.Retry)
+	}
+	if r.Expire > 0 {
+		p.Expire(r.Expire)
+	}
+	if r.NegCache > 0 {
+		p.NegCache(r.NegCache)
+	}
+	return p
+}
+
+func recordParams(subdomain string, ttl uint32) *DNSRequestParams {
+	p := NewDNSParams()
+	if subdomain != "" {
+		p.Subdomain(subdomain)
+	}
+	if ttl > 0 {
+		p.TTL(ttl)
+	}
+	return p
+}
+
+// Typed returns the record as one of ARecord, AAAARecord, CNAMERecord, NSRecord,
+// MXRecord, TXTRecord, SRVRecord or SOARecord
+func (rec *DNSRecord) Typed() (Record, error) {
+	switch rec.Type {
+	case DNSTypeA, DNSTypeAAAA:
+		ip := net.ParseIP(rec.Content)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address of %s record %d: %q", rec.Type, rec.ID, rec.Content)
+		}
+		if rec.Type == DNSTypeA {
+			return ARecord{Subdomain: rec.Subdomain, IP: ip, TTL: rec.TTL}, nil
+		}
+		return AAAARecord{Subdomain: rec.Subdomain, IP: ip, TTL: rec.TTL}, nil
+	case DNSTypeCNAME:
+		return CNAMERecord{Subdomain: rec.Subdomain, Target: rec.Content, TTL: rec.TTL}, nil
+	case DNSTypeNS:
+		return NSRecord{Subdomain: rec.Subdomain, NameServer: rec.Content, TTL: rec.TTL}, nil
+	case DNSTypeMX:
+		priority, _ := rec.Priority.Get()
+		return MXRecord{Subdomain: rec.Subdomain, Priority: priority, Exchange: rec.Content, TTL: rec.TTL}, nil
+	case DNSTypeTXT:
+		return TXTRecord{Subdomain: rec.Subdomain, Text: rec.Content, TTL: rec.TTL}, nil
+	case DNSTypeSRV:
+		labels := strings.SplitN(rec.Subdomain, ".", 3)
+		if len(labels) < 2 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+			return nil, fmt.Errorf("invalid subdomain of SRV record %d: %q", rec.ID, rec.Subdomain)
+		}
+		priority, _ := rec.Priority.Get()
+		r := SRVRecord{
+			Service:  labels[0][1:],
+			Proto:    labels[1][1:],
+			Priority: priority,
+			Weight:   rec.Weight,
+			Port:     rec.Port,
+			Target:   rec.Content,
+			TTL:      rec.TTL,
+		}
+		if len(labels) == 3 {
+			r.Subdomain = labels[2]
+		}
+		return r, nil
+	case DNSTypeSOA:
+		return SOARecord{
+			AdminMail: rec.AdminMail,
+			Refresh:   rec.Refresh,
+			Retry:     rec.Retry,
+			Expire:    rec.Expire,
+			NegCache:  rec.MinTTL,
+			TTL:       rec.TTL,
+		}, nil
+	}
+	return nil, errors.New("unknown record type: " + string(rec.Type))
+}
+
+// DNSAddRecord adds a typed record
+func (c *Client) DNSAddRecord(ctx context.Context, domain string, rec Record) (*DNSResponse, error) {
+	return c.DNSAdd(ctx, domain, rec.Type(), rec.Params())
+}
+
+// DNSEditRecord replaces values of the record with recordID with ones of rec
+func (c *Client) DNSEditRecord(ctx context.Context, domain string, recordID uint32, rec Record) (*DNSResponse, error) {
+	return c.DNSEdit(ctx, domain, recordID, rec.Params())
+}
+
+func (m *MultiClient) DNSAddRecord(ctx context.Context, domain string, rec Record) (*DNSResponse, error) {
+	cli, err := m.Client(domain)
+	if err != nil {
+		return nil, err
+	}
+	return cli.DNSAddRecord(ctx, domain, rec)
+}
+
+func (m *MultiClient) DNSEditRecord(ctx context.Context, domain string, recordID uint32, rec Record) (*DNSResponse, error) {
+	cli, err := m.Client(domain)
+	if err != nil {
+		return nil, err
+	}
+	return cli.DNSEditRecord(ctx, domain, recordID, rec)
+}
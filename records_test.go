@@ -0,0 +1,177 @@
+package yapdd
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestRecord_Params(t *testing.T) {
+	cases := []struct {
+		name      string
+		record    Record
+		expType   DNSRecordType
+		expParams url.Values
+	}{
+		{
+			name:      "A",
+			record:    ARecord{Subdomain: "www", IP: net.ParseIP("127.0.0.1"), TTL: 300},
+			expType:   DNSTypeA,
+			expParams: url.Values{"subdomain": {"www"}, "content": {"127.0.0.1"}, "ttl": {"300"}},
+		},
+		{
+			name:      "AAAA without TTL",
+			record:    AAAARecord{Subdomain: "@", IP: net.ParseIP("::1")},
+			expType:   DNSTypeAAAA,
+			expParams: url.Values{"subdomain": {"@"}, "content": {"::1"}},
+		},
+		{
+			name:      "CNAME",
+			record:    CNAMERecord{Subdomain: "mail", Target: "domain.mail.yandex.net."},
+			expType:   DNSTypeCNAME,
+			expParams: url.Values{"subdomain": {"mail"}, "content": {"domain.mail.yandex.net."}},
+		},
+		{
+			name:      "NS",
+			record:    NSRecord{Subdomain: "sub", NameServer: "dns1.yandex.net."},
+			expType:   DNSTypeNS,
+			expParams: url.Values{"subdomain": {"sub"}, "content": {"dns1.yandex.net."}},
+		},
+		{
+			name:      "MX",
+			record:    MXRecord{Priority: 10, Exchange: "mx.yandex.net."},
+			expType:   DNSTypeMX,
+			expParams: url.Values{"content": {"mx.yandex.net."}, "priority": {"10"}},
+		},
+		{
+			name:      "TXT",
+			record:    TXTRecord{Text: "v=spf1 redirect=_spf.yandex.net"},
+			expType:   DNSTypeTXT,
+			expParams: url.Values{"content": {"v=spf1 redirect=_spf.yandex.net"}},
+		},
+		{
+			name:    "SRV",
+			record:  SRVRecord{Service: "_xmpp-client", Proto: "tcp", Priority: 20, Weight: 5, Port: 5222, Target: "xmpp.domain.com."},
+			expType: DNSTypeSRV,
+			expParams: url.Values{
+				"subdomain": {"_xmpp-client._tcp"},
+				"priority":  {"20"},
+				"weight":    {"5"},
+				"port":      {"5222"},
+				"target":    {"xmpp.domain.com."},
+			},
+		},
+		{
+			name:      "SRV of subdomain",
+			record:    SRVRecord{Service: "sip", Proto: "udp", Subdomain: "voip", Port: 5060, Target: "sip.domain.com."},
+			expType:   DNSTypeSRV,
+			expParams: url.Values{"subdomain": {"_sip._udp.voip"}, "priority": {"0"}, "weight": {"0"}, "port": {"5060"}, "target": {"sip.domain.com."}},
+		},
+		{
+			name:    "SOA",
+			record:  SOARecord{AdminMail: "admin@domain.com", Refresh: 14400, Retry: 900, Expire: 1209600, NegCache: 10800},
+			expType: DNSTypeSOA,
+			expParams: url.Values{
+				"admin_mail": {"admin@domain.com"},
+				"refresh":    {"14400"},
+				"retry":      {"900"},
+				"expire":     {"1209600"},
+				"neg_cache":  {"10800"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.record.Type() != tc.expType {
+				t.Errorf("expected type: %s, got: %s", tc.expType, tc.record.Type())
+			}
+			params := url.Values(*tc.record.Params())
+			if !reflect.DeepEqual(tc.expParams, params) {
+				t.Errorf("expected params: %v, got: %v", tc.expParams, params)
+			}
+		})
+	}
+}
+
+func TestDNSRecord_Typed(t *testing.T) {
+	cases := []struct {
+		name      string
+		json      string
+		expErr    bool
+		expRecord Record
+	}{
+		{
+			name:      "A",
+			json:      `{"record_id": 1, "type": "A", "subdomain": "www", "content": "127.0.0.1", "ttl": 300, "priority": ""}`,
+			expRecord: ARecord{Subdomain: "www", IP: net.ParseIP("127.0.0.1"), TTL: 300},
+		},
+		{
+			name:      "MX",
+			json:      `{"record_id": 2, "type": "MX", "subdomain": "@", "content": "mx.yandex.net.", "ttl": 21600, "priority": 10}`,
+			expRecord: MXRecord{Subdomain: "@", Priority: 10, Exchange: "mx.yandex.net.", TTL: 21600},
+		},
+		{
+			name: "SRV",
+			json: `{"record_id": 3, "type": "SRV", "subdomain": "_sip._udp.voip", "content": "sip.domain.com.",
+				"ttl": 21600, "priority": 20, "weight": "5", "port": 5060}`,
+			expRecord: SRVRecord{
+				Service:   "sip",
+				Proto:     "udp",
+				Subdomain: "voip",
+				Priority:  20,
+				Weight:    5,
+				Port:      5060,
+				Target:    "sip.domain.com.",
+				TTL:       21600,
+			},
+		},
+		{
+			name: "SOA",
+			json: `{"record_id": 4, "type": "SOA", "subdomain": "@", "content": "dns1.yandex.net.", "ttl": 21600,
+				"priority": "", "admin_mail": "admin@domain.com", "refresh": 14400, "retry": 900, "expire": 1209600, "minttl": 14400}`,
+			expRecord: SOARecord{
+				AdminMail: "admin@domain.com",
+				Refresh:   14400,
+				Retry:     900,
+				Expire:    1209600,
+				NegCache:  14400,
+				TTL:       21600,
+			},
+		},
+		{
+			name:   "invalid IP",
+			json:   `{"record_id": 5, "type": "AAAA", "content": "localhost"}`,
+			expErr: true,
+		},
+		{
+			name:   "invalid SRV subdomain",
+			json:   `{"record_id": 6, "type": "SRV", "subdomain": "sip", "content": "sip.domain.com."}`,
+			expErr: true,
+		},
+		{
+			name:   "unknown type",
+			json:   `{"record_id": 7, "type": "PTR", "content": "domain.com."}`,
+			expErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var rec DNSRecord
+			if err := json.Unmarshal([]byte(tc.json), &rec); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			typed, err := rec.Typed()
+			if tc.expErr != (err != nil) {
+				t.Fatalf("expected error: %t, got: %v", tc.expErr, err)
+			}
+			if !reflect.DeepEqual(tc.expRecord, typed) {
+				t.Errorf("expected record: %+v, got: %+v", tc.expRecord, typed)
+			}
+		})
+	}
+}
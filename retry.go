@@ -0,0 +1,154 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+const (
+	defaultRetryAttempts   = 3
+	defaultRetryMinBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 5 * time.Second
+)
+
+// RetryPolicy defines how failed calls are repeated.
+// Only errors for which IsRetryable returns true are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one, 3 by default
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, 100ms by default.
+	// The delay doubles with every attempt and is randomized.
+	MinBackoff time.Duration
+	// MaxBackoff limits the delay between attempts, 5s by default.
+	// A longer delay requested by Retry-After header is honored.
+	MaxBackoff time.Duration
+	// Mutations enables retries of calls that change data (DNSAdd, DNSEdit, DNSDel).
+	// Before every retry the client checks via DNSList whether the change has already
+	// been applied, so a retry never makes a change twice.
+	Mutations bool
+}
+
+// WithRetry enables retries of failed calls.
+// Calls reading data are retried always, calls changing data only if policy.Mutations is set.
+func WithRetry(policy RetryPolicy) Option {
+	return func(cli *Client) {
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = defaultRetryAttempts
+		}
+		if policy.MinBackoff <= 0 {
+			policy.MinBackoff = defaultRetryMinBackoff
+		}
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = defaultRetryMaxBackoff
+		}
+		if policy.MaxBackoff < policy.MinBackoff {
+			policy.MaxBackoff = policy.MinBackoff
+		}
+		cli.retry = &policy
+	}
+}
+
+func (p *RetryPolicy) do(
+	ctx context.Context,
+	op *Operation,
+	v interface{},
+	send func(context.Context, *Operation, interface{}) error,
+) error {
+	for attempt := 1; ; attempt++ {
+		err := send(withAttempt(ctx, attempt), op, v)
+		if err == nil || attempt >= p.MaxAttempts || !p.retryable(op) || !IsRetryable(err) {
+			return err
+		}
+
+		if !sleep(ctx, p.backoff(attempt, err)) {
+			return err
+		}
+
+		resetResult(v)
+
+		if op.Method != http.MethodGet {
+			ok, landedErr := op.landed(ctx, v)
+			if landedErr != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
+			resetResult(v)
+		}
+	}
+}
+
+func (p *RetryPolicy) retryable(op *Operation) bool {
+	if op.Method == http.MethodGet {
+		return true
+	}
+	return p.Mutations && op.landed != nil
+}
+
+// backoff returns delay before the next attempt after attempt failed with err
+func (p *RetryPolicy) backoff(attempt int, err error) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	// random delay between d/2 and d
+	d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		if retryAfter, ok := httpErr.RetryAfter(); ok && retryAfter > d {
+			d = retryAfter
+		}
+	}
+
+	return d
+}
+
+// sleep waits for d and returns false if ctx is done earlier or its deadline
+// comes before d passes
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// resetResult sets the value v points to to zero before the next attempt
+func resetResult(v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+	}
+}
+
+type attemptKey struct{}
+
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// attemptFrom returns the number of the current attempt, starting from 1
+func attemptFrom(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
@@ -0,0 +1,210 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockResponse struct {
+	status int
+	header http.Header
+	body   string
+	err    error
+}
+
+// sequenceTransportMock returns responses in order and records requests as "METHOD URL"
+type sequenceTransportMock struct {
+	responses []mockResponse
+	requests  []string
+}
+
+func (m *sequenceTransportMock) RoundTrip(r *http.Request) (*http.Response, error) {
+	m.requests = append(m.requests, r.Method+" "+r.URL.String())
+	if len(m.responses) == 0 {
+		return nil, errors.New("no more responses")
+	}
+
+	resp := m.responses[0]
+	m.responses = m.responses[1:]
+	if resp.err != nil {
+		return nil, resp.err
+	}
+
+	return &http.Response{
+		StatusCode: resp.status,
+		Status:     fmt.Sprintf("%d %s", resp.status, http.StatusText(resp.status)),
+		Header:     resp.header,
+		Body:       ioutil.NopCloser(strings.NewReader(resp.body)),
+	}, nil
+}
+
+func TestClient_Retry(t *testing.T) {
+	const (
+		listURL = "GET https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com"
+		addURL  = "POST https://pddimp.yandex.ru/api2/admin/dns/add"
+		editURL = "POST https://pddimp.yandex.ru/api2/admin/dns/edit"
+		listOK  = `{"domain": "domain.com", "records": [{"record_id": 1, "type": "A", "subdomain": "www", "content": "1.2.3.4"}], "success": "ok"}`
+		listNo  = `{"domain": "domain.com", "records": [], "success": "ok"}`
+		addOK   = `{"domain": "domain.com", "record": {"record_id": 2, "type": "A", "subdomain": "www", "content": "1.2.3.4"}, "success": "ok"}`
+		listSRV = `{"domain": "domain.com", "records": [{"record_id": 3, "type": "SRV", "subdomain": "_sip._udp",
+			"content": "sip.domain.com.", "priority": 10, "weight": 5, "port": 5060}], "success": "ok"}`
+		editOK = `{"domain": "domain.com", "record_id": 3, "success": "ok"}`
+	)
+
+	unavailable := mockResponse{status: http.StatusServiceUnavailable}
+
+	cases := []struct {
+		name        string
+		policy      RetryPolicy
+		responses   []mockResponse
+		call        func(*Client) (interface{}, error)
+		expErr      bool
+		expResponse interface{}
+		expRequests []string
+	}{
+		{
+			name:      "list is retried",
+			responses: []mockResponse{unavailable, {status: http.StatusOK, body: listNo}},
+			call: func(c *Client) (interface{}, error) {
+				return c.DNSList(context.Background(), "domain.com")
+			},
+			expResponse: &DNSListResponse{Domain: "domain.com", Records: []*DNSRecord{}, Success: "ok"},
+			expRequests: []string{listURL, listURL},
+		},
+		{
+			name:      "attempts are limited",
+			responses: []mockResponse{unavailable, unavailable, unavailable, unavailable},
+			call: func(c *Client) (interface{}, error) {
+				return c.DNSList(context.Background(), "domain.com")
+			},
+			expErr:      true,
+			expResponse: &DNSListResponse{},
+			expRequests: []string{listURL, listURL, listURL},
+		},
+		{
+			name:      "not retryable error",
+			responses: []mockResponse{{status: http.StatusOK, body: `{"success": "error", "error": "no_auth"}`}},
+			call: func(c *Client) (interface{}, error) {
+				return c.DNSList(context.Background(), "domain.com")
+			},
+			expErr:      true,
+			expResponse: &DNSListResponse{Success: "error", Error: "no_auth"},
+			expRequests: []string{listURL},
+		},
+		{
+			name:      "deadline is earlier than next attempt",
+			responses: []mockResponse{{status: http.StatusTooManyRequests, header: http.Header{"Retry-After": {"60"}}}},
+			call: func(c *Client) (interface{}, error) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				return c.DNSList(ctx, "domain.com")
+			},
+			expErr:      true,
+			expResponse: &DNSListResponse{},
+			expRequests: []string{listURL},
+		},
+		{
+			name:      "mutation is not retried by default",
+			responses: []mockResponse{unavailable},
+			call: func(c *Client) (interface{}, error) {
+				return c.DNSAdd(context.Background(), "domain.com", DNSTypeA, NewDNSParams().Subdomain("www").Content("1.2.3.4"))
+			},
+			expErr:      true,
+			expResponse: &DNSResponse{},
+			expRequests: []string{addURL},
+		},
+		{
+			name:      "mutation has landed",
+			policy:    RetryPolicy{Mutations: true},
+			responses: []mockResponse{unavailable, {status: http.StatusOK, body: listOK}},
+			call: func(c *Client) (interface{}, error) {
+				return c.DNSAdd(context.Background(), "domain.com", DNSTypeA, NewDNSParams().Subdomain("www").Content("1.2.3.4"))
+			},
+			expResponse: &DNSResponse{
+				Domain:  "domain.com",
+				Record:  &DNSRecord{ID: 1, Type: DNSTypeA, Subdomain: "www", Content: "1.2.3.4"},
+				Success: "ok",
+			},
+			expRequests: []string{addURL, listURL},
+		},
+		{
+			name:      "mutation has not landed",
+			policy:    RetryPolicy{Mutations: true},
+			responses: []mockResponse{unavailable, {status: http.StatusOK, body: listNo}, {status: http.StatusOK, body: addOK}},
+			call: func(c *Client) (interface{}, error) {
+				return c.DNSAdd(context.Background(), "domain.com", DNSTypeA, NewDNSParams().Subdomain("www").Content("1.2.3.4"))
+			},
+			expResponse: &DNSResponse{
+				Domain:  "domain.com",
+				Record:  &DNSRecord{ID: 2, Type: DNSTypeA, Subdomain: "www", Content: "1.2.3.4"},
+				Success: "ok",
+			},
+			expRequests: []string{addURL, listURL, addURL},
+		},
+		{
+			name:      "edit of SRV port has not landed",
+			policy:    RetryPolicy{Mutations: true},
+			responses: []mockResponse{unavailable, {status: http.StatusOK, body: listSRV}, {status: http.StatusOK, body: editOK}},
+			call: func(c *Client) (interface{}, error) {
+				return c.DNSEdit(context.Background(), "domain.com", 3, NewDNSParams().Weight(5).Port(5061))
+			},
+			expResponse: &DNSResponse{Domain: "domain.com", RecordID: 3, Success: "ok"},
+			expRequests: []string{editURL, listURL, editURL},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &sequenceTransportMock{responses: tc.responses}
+
+			tc.policy.MinBackoff = time.Millisecond
+			tc.policy.MaxBackoff = time.Millisecond
+			cli := New(
+				"token",
+				WithHTTPClient(&http.Client{Transport: transport}),
+				WithRetry(tc.policy),
+			)
+
+			response, err := tc.call(cli)
+			if tc.expErr != (err != nil) {
+				t.Errorf("expected error: %v, got: %v", tc.expErr, err)
+			}
+			if !reflect.DeepEqual(tc.expResponse, response) {
+				t.Errorf("expected response: %+v, got: %+v", tc.expResponse, response)
+			}
+			if !reflect.DeepEqual(tc.expRequests, transport.requests) {
+				t.Errorf("expected requests:\n%v\ngot:\n%v", tc.expRequests, transport.requests)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for attempt, max := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		d := p.backoff(attempt+1, errors.New("fail"))
+		if d < max/2 || d > max {
+			t.Errorf("attempt %d: expected backoff between %v and %v, got: %v", attempt+1, max/2, max, d)
+		}
+	}
+
+	err := &HTTPError{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"5"}}}
+	if d := p.backoff(1, err); d != 5*time.Second {
+		t.Errorf("expected backoff from Retry-After: 5s, got: %v", d)
+	}
+}
@@ -0,0 +1,266 @@
+package yapdd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaIssueKind is a kind of difference between a response and the type it is decoded into
+type SchemaIssueKind string
+
+const (
+	// SchemaUnknownField is a field of response absent in the type
+	SchemaUnknownField SchemaIssueKind = "unknown_field"
+	// SchemaTypeMismatch is a value of JSON type other than expected, e.g. a number encoded as a string
+	SchemaTypeMismatch SchemaIssueKind = "type_mismatch"
+	// SchemaMissingField is a required field absent in a successful response
+	SchemaMissingField SchemaIssueKind = "missing_field"
+)
+
+// SchemaIssue describes a single difference found by strict decoding
+type SchemaIssue struct {
+	Section string
+	Action  string
+	Path    string // e.g. "records[0].ttl"
+	Kind    SchemaIssueKind
+	Detail  string
+}
+
+func (i *SchemaIssue) String() string {
+	s := fmt.Sprintf("%s/%s: %s: %s", i.Section, i.Action, i.Path, i.Kind)
+	if i.Detail != "" {
+		s += " (" + i.Detail + ")"
+	}
+	return s
+}
+
+// SchemaError is returned by a client with strict decoding without a handler
+// when a response does not match the expected type. The result is decoded anyway.
+type SchemaError struct {
+	Issues []SchemaIssue
+}
+
+func (e *SchemaError) Error() string {
+	s := "pdd response does not match schema: " + e.Issues[0].String()
+	if len(e.Issues) > 1 {
+		s += fmt.Sprintf(" and %d more issues", len(e.Issues)-1)
+	}
+	return s
+}
+
+// WithStrictDecoding makes the client compare every successful response with the type
+// it is decoded into. Unknown fields, type mismatches and missing required fields are passed
+// to handler, or returned as *SchemaError if handler is nil.
+// Fields tagged with `yapdd:"required"` are required.
+func WithStrictDecoding(handler func(ctx context.Context, issue SchemaIssue)) Option {
+	return func(cli *Client) {
+		cli.strict = true
+		cli.onSchemaIssue = handler
+	}
+}
+
+// checkSchema reports differences between body and the type of v
+func (c *Client) checkSchema(ctx context.Context, op *Operation, body []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return err
+	}
+
+	var issues []SchemaIssue
+	report := func(path string, kind SchemaIssueKind, detail string) {
+		issues = append(issues, SchemaIssue{
+			Section: op.Section,
+			Action:  op.Action,
+			Path:    path,
+			Kind:    kind,
+			Detail:  detail,
+		})
+	}
+	checkValue(doc, reflect.TypeOf(v), "", report)
+
+	if c.onSchemaIssue != nil {
+		for _, i := range issues {
+			c.onSchemaIssue(ctx, i)
+		}
+		return nil
+	}
+
+	if len(issues) > 0 {
+		return &SchemaError{Issues: issues}
+	}
+	return nil
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func checkValue(doc interface{}, t reflect.Type, path string, report func(string, SchemaIssueKind, string)) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if doc == nil || t.Kind() == reflect.Interface || opaque(t) {
+		return
+	}
+
+	switch val := doc.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			checkObject(val, t, path, report)
+		case reflect.Map:
+			for _, k := range sortedKeys(val) {
+				checkValue(val[k], t.Elem(), joinPath(path, k), report)
+			}
+		default:
+			report(path, SchemaTypeMismatch, "object for "+t.String())
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			report(path, SchemaTypeMismatch, "array for "+t.String())
+			return
+		}
+		for i, e := range val {
+			checkValue(e, t.Elem(), path+"["+strconv.Itoa(i)+"]", report)
+		}
+	case string:
+		if t.Kind() != reflect.String {
+			report(path, SchemaTypeMismatch, "string for "+t.String())
+		}
+	case json.Number:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		default:
+			report(path, SchemaTypeMismatch, "number for "+t.String())
+		}
+	case bool:
+		if t.Kind() != reflect.Bool {
+			report(path, SchemaTypeMismatch, "bool for "+t.String())
+		}
+	}
+}
+
+func checkObject(obj map[string]interface{}, t reflect.Type, path string, report func(string, SchemaIssueKind, string)) {
+	fields := jsonFields(t)
+
+	for _, k := range sortedKeys(obj) {
+		f, ok := fields[k]
+		if !ok {
+			f, ok = fieldFold(fields, k)
+		}
+		if !ok {
+			report(joinPath(path, k), SchemaUnknownField, "")
+			continue
+		}
+		checkValue(obj[k], f.Type, joinPath(path, k), report)
+	}
+
+	if s, ok := obj["success"].(string); ok && s != successOK {
+		return
+	}
+
+	for _, name := range sortedFieldNames(fields) {
+		if fields[name].Tag.Get("yapdd") != "required" {
+			continue
+		}
+		if _, ok := obj[name]; !ok {
+			report(joinPath(path, name), SchemaMissingField, "")
+		}
+	}
+}
+
+// jsonFields returns fields of struct t by their JSON names
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for n, ef := range jsonFields(ft) {
+					if _, ok := fields[n]; !ok {
+						fields[n] = ef
+					}
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// fieldFold finds a field the way encoding/json does, ignoring case
+func fieldFold(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	for name, f := range fields {
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// opaque reports whether t decodes itself and has no exported fields to check
+func opaque(t reflect.Type) bool {
+	if !reflect.PtrTo(t).Implements(unmarshalerType) {
+		return false
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFieldNames(m map[string]reflect.StructField) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,156 @@
+package yapdd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDNSRecord_UnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		name      string
+		json      string
+		expErr    bool
+		expRecord DNSRecord
+	}{
+		{
+			name: "numbers",
+			json: `{"record_id": 1, "ttl": 300, "priority": 10}`,
+			expRecord: DNSRecord{
+				ID:       1,
+				TTL:      300,
+				Priority: DNSPriority{value: 10, ok: true},
+			},
+		},
+		{
+			name: "numbers as strings",
+			json: `{"record_id": "1", "ttl": "300", "priority": "10"}`,
+			expRecord: DNSRecord{
+				ID:       1,
+				TTL:      300,
+				Priority: DNSPriority{value: 10, ok: true},
+			},
+		},
+		{
+			name:      "empty strings",
+			json:      `{"record_id": 1, "ttl": "", "priority": ""}`,
+			expRecord: DNSRecord{ID: 1},
+		},
+		{
+			name:   "not a number",
+			json:   `{"record_id": "one"}`,
+			expErr: true,
+		},
+		{
+			name:   "negative number",
+			json:   `{"ttl": -1}`,
+			expErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var rec DNSRecord
+			err := rec.UnmarshalJSON([]byte(tc.json))
+			if tc.expErr != (err != nil) {
+				t.Fatalf("expected error: %t, got: %v", tc.expErr, err)
+			}
+			if !tc.expErr && !reflect.DeepEqual(tc.expRecord, rec) {
+				t.Errorf("expected record: %+v, got: %+v", tc.expRecord, rec)
+			}
+		})
+	}
+}
+
+func TestDNSResponse_UnmarshalJSON(t *testing.T) {
+	var r DNSResponse
+	if err := r.UnmarshalJSON([]byte(`{"domain": "domain.com", "record_id": "5", "record": {"ttl": "60"}, "success": "ok"}`)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := DNSResponse{Domain: "domain.com", RecordID: 5, Record: &DNSRecord{TTL: 60}, Success: "ok"}
+	if !reflect.DeepEqual(exp, r) {
+		t.Errorf("expected response: %+v, got: %+v", exp, r)
+	}
+}
+
+func TestClient_StrictDecoding(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		expErr    string
+		expIssues []string
+	}{
+		{
+			name:   "matching response",
+			body:   `{"domain": "domain.com", "records": [{"record_id": 1, "type": "A", "content": "127.0.0.1", "priority": ""}], "success": "ok"}`,
+			expErr: "<nil>",
+		},
+		{
+			name:   "number as string",
+			body:   `{"domain": "domain.com", "records": [{"record_id": "1", "type": "A", "content": "127.0.0.1", "ttl": "300"}], "success": "ok"}`,
+			expErr: "pdd response does not match schema: dns/list: records[0].record_id: type_mismatch (string for uint32) and 1 more issues",
+			expIssues: []string{
+				"dns/list: records[0].record_id: type_mismatch (string for uint32)",
+				"dns/list: records[0].ttl: type_mismatch (string for uint32)",
+			},
+		},
+		{
+			name:   "unknown and missing fields",
+			body:   `{"domain": "domain.com", "records": [{"record_id": 1, "type": "A", "value": "127.0.0.1"}], "total": 1, "success": "ok"}`,
+			expErr: "pdd response does not match schema: dns/list: records[0].value: unknown_field and 2 more issues",
+			expIssues: []string{
+				"dns/list: records[0].value: unknown_field",
+				"dns/list: records[0].content: missing_field",
+				"dns/list: total: unknown_field",
+			},
+		},
+		{
+			name:   "api error is not checked",
+			body:   `{"domain": "domain.com", "success": "error", "error": "no_auth"}`,
+			expErr: "pdd api error: dns/list (domain domain.com): no_auth",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &httpTransportMock{}
+			newResponse := func() *http.Response {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(tc.body)),
+				}
+			}
+
+			transport.response = newResponse()
+			cli := New("token", WithHTTPClient(&http.Client{Transport: transport}), WithStrictDecoding(nil))
+			r, err := cli.DNSList(context.Background(), "domain.com")
+			if tc.expErr != fmt.Sprint(err) {
+				t.Errorf("expected error: %s, got: %v", tc.expErr, err)
+			}
+			if err == nil && len(r.Records) != 1 {
+				t.Errorf("expected decoded records, got: %+v", r.Records)
+			}
+
+			var issues []string
+			transport.response = newResponse()
+			cli = New("token",
+				WithHTTPClient(&http.Client{Transport: transport}),
+				WithStrictDecoding(func(_ context.Context, issue SchemaIssue) {
+					issues = append(issues, issue.String())
+				}),
+			)
+			_, err = cli.DNSList(context.Background(), "domain.com")
+			if _, ok := err.(*SchemaError); ok {
+				t.Errorf("unexpected schema error with handler: %s", err)
+			}
+			if !reflect.DeepEqual(tc.expIssues, issues) {
+				t.Errorf("expected issues: %q, got: %q", tc.expIssues, issues)
+			}
+		})
+	}
+}
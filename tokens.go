@@ -0,0 +1,235 @@
+package yapdd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCommandTimeout limits the run of a credential helper of CommandToken
+const defaultCommandTimeout = 30 * time.Second
+
+// TokenSource provides a token for every request.
+// Implementations must be safe for concurrent use.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc is an adapter to use a function as TokenSource
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// WithTokenSource makes the client take PDD token from ts for every request
+// instead of the token passed to New
+func WithTokenSource(ts TokenSource) Option {
+	return func(cli *Client) {
+		cli.pddToken = ts
+	}
+}
+
+// AsRegistrarWithTokenSource is AsRegistrar taking OAuth token from ts for every request
+func AsRegistrarWithTokenSource(ts TokenSource) Option {
+	return func(cli *Client) {
+		cli.clientType = clientTypeRegistrar
+		cli.oauthToken = ts
+	}
+}
+
+// StaticTokenSource returns the same token until it is replaced with Set
+type StaticTokenSource struct {
+	mu    sync.RWMutex
+	token string
+}
+
+// NewStaticToken returns StaticTokenSource with token
+func NewStaticToken(token string) *StaticTokenSource {
+	return &StaticTokenSource{token: token}
+}
+
+func (s *StaticTokenSource) Token(context.Context) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.token, nil
+}
+
+// Set replaces the token, requests started after Set use the new one
+func (s *StaticTokenSource) Set(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = token
+}
+
+// EnvToken returns TokenSource reading the token from environment variable name on every request
+func EnvToken(name string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (string, error) {
+		token := strings.TrimSpace(os.Getenv(name))
+		if token == "" {
+			return "", fmt.Errorf("environment variable %s is empty", name)
+		}
+		return token, nil
+	})
+}
+
+// FileToken returns TokenSource reading the token from file path.
+// The file is read again when its size or modification time changes.
+func FileToken(path string) TokenSource {
+	return &fileTokenSource{path: path}
+}
+
+type fileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	size    int64
+	modTime time.Time
+}
+
+func (s *fileTokenSource) Token(context.Context) (string, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && info.Size() == s.size && info.ModTime().Equal(s.modTime) {
+		return s.token, nil
+	}
+
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", s.path)
+	}
+
+	s.token = token
+	s.size = info.Size()
+	s.modTime = info.ModTime()
+	return s.token, nil
+}
+
+// CommandToken returns TokenSource getting the token from standard output of
+// an external credential helper. The token is cached for ttl, the command is
+// run again when the cache expires. Zero ttl means the command is run for every request.
+// Concurrent requests share a single run of the command, which is killed after 30 seconds.
+func CommandToken(ttl time.Duration, name string, args ...string) TokenSource {
+	return CommandTokenWithTimeout(ttl, defaultCommandTimeout, name, args...)
+}
+
+// CommandTokenWithTimeout is CommandToken killing the command after timeout
+func CommandTokenWithTimeout(ttl, timeout time.Duration, name string, args ...string) TokenSource {
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	return &commandTokenSource{
+		name:    name,
+		args:    args,
+		ttl:     ttl,
+		timeout: timeout,
+		now:     time.Now,
+	}
+}
+
+type commandTokenSource struct {
+	name    string
+	args    []string
+	ttl     time.Duration
+	timeout time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+	refresh *tokenRefresh // the running helper shared by concurrent callers
+}
+
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// Token returns the cached token or waits for the helper. The helper is run once for all
+// concurrent callers, and a caller giving up because its ctx is done doesn't stop it for others.
+func (s *commandTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	if s.token != "" && s.now().Before(s.expires) {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+
+	r := s.refresh
+	if r == nil {
+		r = &tokenRefresh{done: make(chan struct{})}
+		s.refresh = r
+		go s.run(r)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.token, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (s *commandTokenSource) run(r *tokenRefresh) {
+	r.token, r.err = s.command()
+
+	s.mu.Lock()
+	if r.err == nil {
+		s.token = r.token
+		s.expires = s.now().Add(s.ttl)
+	}
+	s.refresh = nil
+	s.mu.Unlock()
+
+	close(r.done)
+}
+
+// command runs the helper with its own timeout, as it is shared by callers with different contexts
+func (s *commandTokenSource) command() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.name, s.args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("credential helper %s: killed after %s: %w", s.name, s.timeout, ctx.Err())
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("credential helper %s: %w: %s", s.name, err, msg)
+		}
+		return "", fmt.Errorf("credential helper %s: %w", s.name, err)
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", errors.New("credential helper " + s.name + " returned empty token")
+	}
+	return token, nil
+}
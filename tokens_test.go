@@ -0,0 +1,223 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_TokenSource(t *testing.T) {
+	pddToken := NewStaticToken("first")
+	transport := &httpTransportMock{}
+	cli := New(
+		"ignored",
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithTokenSource(pddToken),
+		AsRegistrarWithTokenSource(TokenSourceFunc(func(context.Context) (string, error) {
+			return "oauth", nil
+		})),
+	)
+
+	for _, exp := range []string{"first", "second"} {
+		pddToken.Set(exp)
+		transport.response = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"success": "ok"}`)),
+		}
+
+		if _, err := cli.DNSList(context.Background(), "domain.com"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if v := transport.request.Header["PddToken"]; !reflect.DeepEqual(v, []string{exp}) {
+			t.Errorf("expected PddToken: %s, got: %v", exp, v)
+		}
+		if v := transport.request.Header.Get("Authorization"); v != "OAuth oauth" {
+			t.Errorf("unexpected Authorization: %s", v)
+		}
+	}
+}
+
+func TestClient_TokenSourceError(t *testing.T) {
+	transport := &httpTransportMock{}
+	cli := New(
+		"",
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithTokenSource(EnvToken("YAPDD_TEST_ABSENT_TOKEN")),
+	)
+
+	if _, err := cli.DNSList(context.Background(), "domain.com"); err == nil {
+		t.Errorf("expected error")
+	}
+	if transport.request != nil {
+		t.Errorf("expected no request to be sent without token")
+	}
+}
+
+func TestStaticTokenSource_Concurrent(t *testing.T) {
+	ts := NewStaticToken("token")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ts.Set("rotated")
+		}()
+		go func() {
+			defer wg.Done()
+			if token, _ := ts.Token(context.Background()); token != "token" && token != "rotated" {
+				t.Errorf("unexpected token: %s", token)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestEnvToken(t *testing.T) {
+	os.Setenv("YAPDD_TEST_TOKEN", " token\n")
+	defer os.Unsetenv("YAPDD_TEST_TOKEN")
+
+	ts := EnvToken("YAPDD_TEST_TOKEN")
+	if token, err := ts.Token(context.Background()); err != nil || token != "token" {
+		t.Errorf("expected token, got: %q, %v", token, err)
+	}
+
+	os.Setenv("YAPDD_TEST_TOKEN", "")
+	if _, err := ts.Token(context.Background()); err == nil {
+		t.Errorf("expected error for empty variable")
+	}
+}
+
+func TestFileToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yapdd")
+	if err != nil {
+		t.Fatalf("can't create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	if err = ioutil.WriteFile(path, []byte("first\n"), 0600); err != nil {
+		t.Fatalf("can't write token: %s", err)
+	}
+
+	ts := FileToken(path)
+	if token, err := ts.Token(context.Background()); err != nil || token != "first" {
+		t.Errorf("expected first token, got: %q, %v", token, err)
+	}
+
+	if err = ioutil.WriteFile(path, []byte("rotated\n"), 0600); err != nil {
+		t.Fatalf("can't write token: %s", err)
+	}
+	if token, err := ts.Token(context.Background()); err != nil || token != "rotated" {
+		t.Errorf("expected rotated token, got: %q, %v", token, err)
+	}
+
+	os.Remove(path)
+	if _, err := ts.Token(context.Background()); err == nil {
+		t.Errorf("expected error for removed file")
+	}
+}
+
+func TestCommandToken(t *testing.T) {
+	now := time.Unix(0, 0)
+	ts := CommandToken(time.Minute, "sh", "-c", "date +%s%N").(*commandTokenSource)
+	ts.now = func() time.Time { return now }
+
+	first, err := ts.Token(context.Background())
+	if err != nil || first == "" {
+		t.Fatalf("expected token, got: %q, %v", first, err)
+	}
+
+	if token, _ := ts.Token(context.Background()); token != first {
+		t.Errorf("expected cached token: %s, got: %s", first, token)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if token, _ := ts.Token(context.Background()); token == first {
+		t.Errorf("expected new token after cache expired")
+	}
+
+	_, err = CommandToken(0, "sh", "-c", "echo denied >&2; exit 1").Token(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected error with helper output, got: %v", err)
+	}
+}
+
+func TestCommandToken_Concurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yapdd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	runs := filepath.Join(dir, "runs")
+	ts := CommandToken(time.Minute, "sh", "-c", "echo run >> "+runs+"; sleep 0.2; echo token")
+
+	// the first caller gives up at once, the helper keeps running for others
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ts.Token(ctx); err != context.Canceled {
+		t.Errorf("expected error: %v, got: %v", context.Canceled, err)
+	}
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 5)
+	errs := make([]error, 5)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], errs[i] = ts.Token(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range tokens {
+		if tokens[i] != "token" || errs[i] != nil {
+			t.Errorf("caller %d: expected token, got: %q, %v", i, tokens[i], errs[i])
+		}
+	}
+
+	data, err := ioutil.ReadFile(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "run"); n != 1 {
+		t.Errorf("expected the helper to run once, got: %d", n)
+	}
+}
+
+func TestCommandToken_Timeout(t *testing.T) {
+	ts := CommandTokenWithTimeout(time.Minute, 100*time.Millisecond, "sleep", "10")
+
+	start := time.Now()
+	_, err := ts.Token(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error: %v, got: %v", context.DeadlineExceeded, err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("expected the helper to be killed, waited: %v", d)
+	}
+
+	// the hung run is not shared by later calls
+	s := ts.(*commandTokenSource)
+	s.mu.Lock()
+	refresh := s.refresh
+	s.mu.Unlock()
+	if refresh != nil {
+		t.Errorf("expected no running helper after timeout")
+	}
+
+	s.name, s.args = "echo", []string{"token"}
+	if token, err := ts.Token(context.Background()); token != "token" || err != nil {
+		t.Errorf("expected token from a new run, got: %q, %v", token, err)
+	}
+}
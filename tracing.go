@@ -0,0 +1,91 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// Attribute is a key-value pair describing a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts spans. It can be bridged to OpenTelemetry or another tracing library.
+// The client starts a span for every logical operation named after the method, e.g. "yapdd.DNSAdd",
+// and nested spans "yapdd.attempt" for every attempt and "yapdd.http" for every HTTP round trip.
+// The context returned by Start is passed to nested spans and to the HTTP request.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a traced unit of work
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	// End finishes the span, err is nil if the work succeeded
+	End(err error)
+}
+
+// WithTracer makes the client trace its calls with t
+func WithTracer(t Tracer) Option {
+	return func(cli *Client) {
+		cli.tracer = t
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) End(error)                  {}
+
+func (c *Client) startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return c.tracer.Start(ctx, name, attrs...)
+}
+
+func (c *Client) startOperationSpan(ctx context.Context, op *Operation) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	attrs := []Attribute{
+		{Key: "pdd.section", Value: op.Section},
+		{Key: "pdd.action", Value: op.Action},
+		{Key: "pdd.domain", Value: op.Domain},
+	}
+	if t := op.Params.Get("type"); t != "" {
+		attrs = append(attrs, Attribute{Key: "dns.record_type", Value: t})
+	}
+	if id := op.Params.Get("record_id"); id != "" {
+		attrs = append(attrs, Attribute{Key: "dns.record_id", Value: id})
+	}
+
+	return c.tracer.Start(ctx, "yapdd."+op.Name, attrs...)
+}
+
+// resultAttributes describes the result of an operation
+func resultAttributes(v interface{}, err error) []Attribute {
+	attrs := []Attribute{{Key: "pdd.result", Value: outcome(err)}}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		attrs = append(attrs, Attribute{Key: "pdd.error", Value: string(apiErr.Code)})
+	}
+
+	if r, ok := v.(*DNSResponse); ok && err == nil {
+		switch {
+		case r.Record != nil:
+			attrs = append(attrs,
+				Attribute{Key: "dns.record_id", Value: strconv.Itoa(int(r.Record.ID))},
+				Attribute{Key: "dns.record_type", Value: string(r.Record.Type)},
+			)
+		case r.RecordID != 0:
+			attrs = append(attrs, Attribute{Key: "dns.record_id", Value: strconv.Itoa(int(r.RecordID))})
+		}
+	}
+
+	return attrs
+}
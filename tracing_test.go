@@ -0,0 +1,125 @@
+package yapdd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type spanKey struct{}
+
+type spanMock struct {
+	name   string
+	parent string
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *spanMock) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *spanMock) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+type tracerMock struct {
+	spans []*spanMock
+}
+
+func (t *tracerMock) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &spanMock{name: name, attrs: map[string]interface{}{}}
+	if parent, ok := ctx.Value(spanKey{}).(*spanMock); ok {
+		s.parent = parent.name
+	}
+	s.SetAttributes(attrs...)
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// contextTransportMock records the span found in the request context
+type contextTransportMock struct {
+	sequenceTransportMock
+	spans []*spanMock
+}
+
+func (m *contextTransportMock) RoundTrip(r *http.Request) (*http.Response, error) {
+	s, _ := r.Context().Value(spanKey{}).(*spanMock)
+	m.spans = append(m.spans, s)
+	return m.sequenceTransportMock.RoundTrip(r)
+}
+
+func TestClient_Tracer(t *testing.T) {
+	// the record is still present after the first failed attempt, so deletion is repeated
+	transport := &contextTransportMock{
+		sequenceTransportMock: sequenceTransportMock{
+			responses: []mockResponse{
+				{status: http.StatusBadGateway},
+				{status: http.StatusOK, body: `{"domain": "domain.com", "records": [{"record_id": 1}], "success": "ok"}`},
+				{status: http.StatusOK, body: `{"domain": "domain.com", "record_id": 1, "success": "ok"}`},
+			},
+		},
+	}
+	tracer := &tracerMock{}
+
+	cli := New(
+		"token",
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithRetry(RetryPolicy{MinBackoff: time.Millisecond, Mutations: true}),
+		WithTracer(tracer),
+	)
+
+	if _, err := cli.DNSDel(context.Background(), "domain.com", 1); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var got []string
+	for _, s := range tracer.spans {
+		if !s.ended {
+			t.Errorf("span %s is not ended", s.name)
+		}
+		got = append(got, fmt.Sprintf("%s<-%s err=%v", s.name, s.parent, s.err != nil))
+	}
+
+	exp := []string{
+		"yapdd.DNSDel<- err=false",
+		"yapdd.attempt<-yapdd.DNSDel err=true",
+		"yapdd.http<-yapdd.attempt err=true",
+		"yapdd.DNSList<-yapdd.DNSDel err=false",
+		"yapdd.attempt<-yapdd.DNSList err=false",
+		"yapdd.http<-yapdd.attempt err=false",
+		"yapdd.attempt<-yapdd.DNSDel err=false",
+		"yapdd.http<-yapdd.attempt err=false",
+	}
+	if !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected spans:\n%v\ngot:\n%v", exp, got)
+	}
+
+	expAttrs := map[string]interface{}{
+		"pdd.section":   "dns",
+		"pdd.action":    "del",
+		"pdd.domain":    "domain.com",
+		"pdd.result":    OutcomeOK,
+		"dns.record_id": "1",
+	}
+	if !reflect.DeepEqual(expAttrs, tracer.spans[0].attrs) {
+		t.Errorf("expected attributes:\n%v\ngot:\n%v", expAttrs, tracer.spans[0].attrs)
+	}
+
+	if status := tracer.spans[2].attrs["http.status_code"]; status != http.StatusBadGateway {
+		t.Errorf("expected http status attribute: %d, got: %v", http.StatusBadGateway, status)
+	}
+
+	for i, s := range transport.spans {
+		if s == nil || s.name != "yapdd.http" {
+			t.Errorf("request %d: expected http span in request context, got: %+v", i, s)
+		}
+	}
+}
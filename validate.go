@@ -0,0 +1,326 @@
+package yapdd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TTL range accepted by PDD
+const (
+	MinDNSTTL = 900
+	MaxDNSTTL = 1209600
+)
+
+const (
+	maxTXTLength    = 4096 // limit of the whole TXT content
+	maxTXTString    = 255  // limit of a single quoted string of TXT content
+	maxHostname     = 253
+	maxLabel        = 63
+	maxServiceLabel = 15
+)
+
+// FieldError describes an invalid parameter
+type FieldError struct {
+	Field   string
+	Value   string
+	Message string
+}
+
+func (e *FieldError) String() string {
+	return fmt.Sprintf("%s %q: %s", e.Field, e.Value, e.Message)
+}
+
+// ValidationError is returned by DNSAdd and DNSEdit without sending a request
+// when parameters are invalid for the record type
+type ValidationError struct {
+	Type   DNSRecordType // empty for DNSEdit
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	s := make([]string, 0, len(e.Fields))
+	for i := range e.Fields {
+		s = append(s, e.Fields[i].String())
+	}
+
+	if e.Type == "" {
+		return "invalid DNS record: " + strings.Join(s, "; ")
+	}
+	return fmt.Sprintf("invalid %s record: %s", e.Type, strings.Join(s, "; "))
+}
+
+// WithoutValidation disables checks of DNSAdd and DNSEdit parameters before sending,
+// so anything PDD accepts can be sent
+func WithoutValidation() Option {
+	return func(cli *Client) {
+		cli.noValidation = true
+	}
+}
+
+// validateRecord checks parameters of a record of type recordType.
+// The type is unknown for DNSEdit, so content is checked only if recordType is not empty.
+func validateRecord(recordType DNSRecordType, values url.Values) error {
+	v := validator{values: values}
+
+	if recordType != "" && !recordType.valid() {
+		v.fail("type", string(recordType), "unknown record type")
+	}
+
+	if s, ok := v.get("subdomain"); ok {
+		if recordType == DNSTypeSRV {
+			v.checkSRVName(s)
+		} else {
+			v.checkSubdomain(s)
+		}
+	} else if recordType == DNSTypeSRV {
+		v.fail("subdomain", "", "SRV record requires subdomain _service._proto")
+	}
+
+	if ttl, ok := v.get("ttl"); ok {
+		n, err := strconv.ParseUint(ttl, 10, 32)
+		if err != nil || n < MinDNSTTL || n > MaxDNSTTL {
+			v.fail("ttl", ttl, fmt.Sprintf("must be a number from %d to %d", MinDNSTTL, MaxDNSTTL))
+		}
+	}
+
+	for _, k := range []string{"priority", "weight"} {
+		if s, ok := v.get(k); ok {
+			if _, err := strconv.ParseUint(s, 10, 16); err != nil {
+				v.fail(k, s, "must be a number from 0 to 65535")
+			}
+		}
+	}
+
+	if port, ok := v.get("port"); ok {
+		n, err := strconv.ParseUint(port, 10, 16)
+		if err != nil || n == 0 {
+			v.fail("port", port, "must be a number from 1 to 65535")
+		}
+	} else if recordType == DNSTypeSRV {
+		v.fail("port", "", "SRV record requires port")
+	}
+
+	content, hasContent := v.get("content")
+	if !hasContent && recordType != "" && recordType != DNSTypeSOA && recordType != DNSTypeSRV {
+		v.fail("content", "", "is required")
+	}
+
+	switch recordType {
+	case DNSTypeA:
+		if ip := net.ParseIP(content); hasContent && (ip == nil || ip.To4() == nil || strings.Contains(content, ":")) {
+			v.fail("content", content, "must be an IPv4 address")
+		}
+	case DNSTypeAAAA:
+		if ip := net.ParseIP(content); hasContent && (ip == nil || !strings.Contains(content, ":")) {
+			v.fail("content", content, "must be an IPv6 address")
+		}
+	case DNSTypeCNAME, DNSTypeNS, DNSTypeMX:
+		if hasContent && !isHostname(content) {
+			v.fail("content", content, "must be a hostname")
+		}
+	case DNSTypeTXT:
+		if hasContent {
+			v.checkTXT(content)
+		}
+	case DNSTypeSRV:
+		target, ok := v.get("target")
+		if !ok {
+			v.fail("target", "", "SRV record requires target")
+		} else if target != "." && !isHostname(target) {
+			v.fail("target", target, "must be a hostname or \".\"")
+		}
+	}
+
+	if len(v.fields) > 0 {
+		return &ValidationError{Type: recordType, Fields: v.fields}
+	}
+	return nil
+}
+
+// validator collects errors of fields
+type validator struct {
+	values url.Values
+	fields []FieldError
+}
+
+func (v *validator) get(key string) (string, bool) {
+	if s, ok := v.values[key]; ok && len(s) > 0 {
+		return s[0], true
+	}
+	return "", false
+}
+
+func (v *validator) fail(field, value, message string) {
+	v.fields = append(v.fields, FieldError{Field: field, Value: value, Message: message})
+}
+
+// checkSubdomain checks labels of subdomain; "*" is allowed only as the leftmost label
+func (v *validator) checkSubdomain(s string) {
+	if s == "" || s == "@" {
+		return
+	}
+
+	name := strings.TrimSuffix(s, ".")
+	if len(name) > maxHostname {
+		v.fail("subdomain", s, "is too long")
+		return
+	}
+
+	for i, label := range strings.Split(name, ".") {
+		if label == "*" {
+			if i > 0 {
+				v.fail("subdomain", s, "wildcard must be the leftmost label")
+				return
+			}
+			continue
+		}
+		if msg := checkLabel(label); msg != "" {
+			v.fail("subdomain", s, msg)
+			return
+		}
+	}
+}
+
+// checkSRVName checks subdomain of SRV record: _service._proto[.subdomain]
+func (v *validator) checkSRVName(s string) {
+	labels := strings.SplitN(strings.TrimSuffix(s, "."), ".", 3)
+	if len(labels) < 2 {
+		v.fail("subdomain", s, "must be _service._proto")
+		return
+	}
+
+	service, proto := labels[0], labels[1]
+	if !strings.HasPrefix(service, "_") || len(service) < 2 || len(service) > maxServiceLabel+1 ||
+		!isServiceName(service[1:]) {
+		v.fail("subdomain", s, "service must be an underscore and up to 15 letters, digits and hyphens")
+	}
+	if !strings.HasPrefix(proto, "_") || len(proto) < 2 || !isAlnum(proto[1:]) {
+		v.fail("subdomain", s, "proto must be an underscore and letters or digits, e.g. _tcp")
+	}
+
+	if len(labels) == 3 {
+		for _, label := range strings.Split(labels[2], ".") {
+			if msg := checkLabel(label); msg != "" {
+				v.fail("subdomain", s, msg)
+				return
+			}
+		}
+	}
+}
+
+// checkTXT checks length of TXT content and balance of quotes if content is quoted
+func (v *validator) checkTXT(s string) {
+	if s == "" {
+		v.fail("content", s, "is required")
+		return
+	}
+	if len(s) > maxTXTLength {
+		v.fail("content", s, fmt.Sprintf("is longer than %d bytes", maxTXTLength))
+		return
+	}
+	if !strings.HasPrefix(s, `"`) {
+		if strings.Contains(strings.ReplaceAll(s, `\"`, ""), `"`) {
+			v.fail("content", s, "unquoted content must not contain unescaped quotes")
+		}
+		return
+	}
+
+	strs, err := splitQuoted(s)
+	if err != nil {
+		v.fail("content", s, err.Error())
+		return
+	}
+	for _, str := range strs {
+		if len(str) > maxTXTString {
+			v.fail("content", s, fmt.Sprintf("quoted string is longer than %d bytes", maxTXTString))
+			return
+		}
+	}
+}
+
+// splitQuoted splits content like `"a" "b"` to unquoted strings
+func splitQuoted(s string) ([]string, error) {
+	var strs []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		if s[0] != '"' {
+			return nil, fmt.Errorf("unexpected text outside of quotes")
+		}
+
+		var b strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			b.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return nil, fmt.Errorf("unbalanced quotes")
+		}
+
+		strs = append(strs, b.String())
+		s = s[i+1:]
+	}
+	return strs, nil
+}
+
+// isHostname reports whether s is a domain name with an optional trailing dot
+func isHostname(s string) bool {
+	name := strings.TrimSuffix(s, ".")
+	if name == "" || len(name) > maxHostname {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if checkLabel(label) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// checkLabel returns a description of the problem with a domain name label or ""
+func checkLabel(label string) string {
+	switch {
+	case label == "":
+		return "empty label"
+	case len(label) > maxLabel:
+		return fmt.Sprintf("label is longer than %d characters", maxLabel)
+	case label[0] == '-' || label[len(label)-1] == '-':
+		return "label must not start or end with a hyphen"
+	}
+
+	for _, r := range label {
+		if !isAlnumRune(r) && r != '-' && r != '_' {
+			return fmt.Sprintf("label contains invalid character %q", r)
+		}
+	}
+	return ""
+}
+
+func isServiceName(s string) bool {
+	if s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	for _, r := range s {
+		if !isAlnumRune(r) && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if !isAlnumRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnumRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
@@ -0,0 +1,179 @@
+package yapdd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestValidateRecord(t *testing.T) {
+	long := strings.Repeat("a", 64)
+
+	cases := []struct {
+		name       string
+		recordType DNSRecordType
+		params     *DNSRequestParams
+		expErr     string
+	}{
+		{
+			name:       "valid A",
+			recordType: DNSTypeA,
+			params:     NewDNSParams().Subdomain("www").Content("1.2.3.4").TTL(900),
+		},
+		{
+			name:       "A with IPv6 and short TTL",
+			recordType: DNSTypeA,
+			params:     NewDNSParams().Content("::1").TTL(60),
+			expErr:     `invalid A record: ttl "60": must be a number from 900 to 1209600; content "::1": must be an IPv4 address`,
+		},
+		{
+			name:       "A without content",
+			recordType: DNSTypeA,
+			params:     NewDNSParams().Subdomain("www"),
+			expErr:     `invalid A record: content "": is required`,
+		},
+		{
+			name:       "valid AAAA",
+			recordType: DNSTypeAAAA,
+			params:     NewDNSParams().Content("2001:db8::1"),
+		},
+		{
+			name:       "AAAA with IPv4",
+			recordType: DNSTypeAAAA,
+			params:     NewDNSParams().Content("1.2.3.4"),
+			expErr:     `invalid AAAA record: content "1.2.3.4": must be an IPv6 address`,
+		},
+		{
+			name:       "valid CNAME",
+			recordType: DNSTypeCNAME,
+			params:     NewDNSParams().Subdomain("mail").Content("domain.mail.yandex.net."),
+		},
+		{
+			name:       "CNAME to URL",
+			recordType: DNSTypeCNAME,
+			params:     NewDNSParams().Subdomain("mail").Content("http://mail.yandex.ru/"),
+			expErr:     `invalid CNAME record: content "http://mail.yandex.ru/": must be a hostname`,
+		},
+		{
+			name:       "MX with bad priority",
+			recordType: DNSTypeMX,
+			params:     &DNSRequestParams{"content": {"mx.yandex.net."}, "priority": {"70000"}},
+			expErr:     `invalid MX record: priority "70000": must be a number from 0 to 65535`,
+		},
+		{
+			name:       "NS with long label",
+			recordType: DNSTypeNS,
+			params:     NewDNSParams().Content(long + ".domain.com"),
+			expErr:     `invalid NS record: content "` + long + `.domain.com": must be a hostname`,
+		},
+		{
+			name:       "valid wildcard",
+			recordType: DNSTypeA,
+			params:     NewDNSParams().Subdomain("*.sub").Content("1.2.3.4"),
+		},
+		{
+			name:       "misplaced wildcard",
+			recordType: DNSTypeA,
+			params:     NewDNSParams().Subdomain("sub.*").Content("1.2.3.4"),
+			expErr:     `invalid A record: subdomain "sub.*": wildcard must be the leftmost label`,
+		},
+		{
+			name:       "bad subdomain label",
+			recordType: DNSTypeTXT,
+			params:     NewDNSParams().Subdomain("-www..x").Content("text"),
+			expErr:     `invalid TXT record: subdomain "-www..x": label must not start or end with a hyphen`,
+		},
+		{
+			name:       "valid quoted TXT",
+			recordType: DNSTypeTXT,
+			params:     NewDNSParams().Content(`"v=DKIM1; k=rsa; " "p=MIGf\"MA0"`),
+		},
+		{
+			name:       "TXT with unbalanced quotes",
+			recordType: DNSTypeTXT,
+			params:     NewDNSParams().Content(`"v=spf1 -all`),
+			expErr:     `invalid TXT record: content "\"v=spf1 -all": unbalanced quotes`,
+		},
+		{
+			name:       "TXT with long quoted string",
+			recordType: DNSTypeTXT,
+			params:     NewDNSParams().Content(`"` + strings.Repeat("a", 256) + `"`),
+			expErr:     `invalid TXT record: content "\"` + strings.Repeat("a", 256) + `\"": quoted string is longer than 255 bytes`,
+		},
+		{
+			name:       "valid SRV",
+			recordType: DNSTypeSRV,
+			params:     NewDNSParams().Subdomain("_xmpp-client._tcp").Priority(10).Weight(5).Port(5222).Target("xmpp.domain.com."),
+		},
+		{
+			name:       "SRV without underscores and port",
+			recordType: DNSTypeSRV,
+			params:     NewDNSParams().Subdomain("sip.udp").Target("sip.domain.com"),
+			expErr: `invalid SRV record: subdomain "sip.udp": service must be an underscore and up to 15 letters, digits and hyphens; ` +
+				`subdomain "sip.udp": proto must be an underscore and letters or digits, e.g. _tcp; port "": SRV record requires port`,
+		},
+		{
+			name:       "SRV with zero port and no target",
+			recordType: DNSTypeSRV,
+			params:     NewDNSParams().Subdomain("_sip._udp").Port(0),
+			expErr:     `invalid SRV record: port "0": must be a number from 1 to 65535; target "": SRV record requires target`,
+		},
+		{
+			name:       "unknown type",
+			recordType: DNSRecordType("PTR"),
+			params:     NewDNSParams().Content("domain.com"),
+			expErr:     `invalid PTR record: type "PTR": unknown record type`,
+		},
+		{
+			name:   "edit checks fields independent of type",
+			params: NewDNSParams().Content("anything").TTL(2000000),
+			expErr: `invalid DNS record: ttl "2000000": must be a number from 900 to 1209600`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateRecord(tc.recordType, url.Values(*tc.params))
+			if err == nil {
+				if tc.expErr != "" {
+					t.Errorf("expected error: %s, got: nil", tc.expErr)
+				}
+				return
+			}
+			if err.Error() != tc.expErr {
+				t.Errorf("expected error: %s, got: %s", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestClient_Validation(t *testing.T) {
+	transport := &httpTransportMock{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"success": "error", "error": "bad_content"}`)),
+		},
+	}
+
+	cli := New("token", WithHTTPClient(&http.Client{Transport: transport}))
+	_, err := cli.DNSAdd(context.Background(), "domain.com", DNSTypeA, NewDNSParams().Content("localhost"))
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "content" {
+		t.Errorf("expected validation error of content, got: %v", err)
+	}
+	if transport.request != nil {
+		t.Errorf("expected no request, got: %s", transport.request.URL)
+	}
+
+	cli = New("token", WithHTTPClient(&http.Client{Transport: transport}), WithoutValidation())
+	_, err = cli.DNSAdd(context.Background(), "domain.com", DNSTypeA, NewDNSParams().Content("localhost"))
+	if fmt.Sprint(err) != "pdd api error: dns/add (domain domain.com): bad_content" {
+		t.Errorf("expected api error, got: %v", err)
+	}
+}
@@ -4,11 +4,15 @@ package yapdd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
 const (
@@ -16,17 +20,36 @@ const (
 	clientTypeRegistrar = "registrar"
 )
 
+const defaultBaseURL = "https://pddimp.yandex.ru/api2/"
+
 type Client struct {
-	httpCli    *http.Client
-	clientType string
-	pddToken   string
-	oauthToken string
+	httpCli      *http.Client
+	clientType   string
+	pddToken     TokenSource
+	oauthToken   TokenSource
+	baseURL      string
+	fallbackURLs []string
+	retry        *RetryPolicy
+	rateLimit    *rateLimit
+	rateLimitKey string
+	breaker      *circuitBreaker
+	middlewares  []Middleware
+	logger       Logger
+	logLevel     LogLevel
+	metrics      MetricsRecorder
+	tracer       Tracer
+	dryRun       bool
+	noValidation bool
+
+	strict        bool
+	onSchemaIssue func(ctx context.Context, issue SchemaIssue)
 }
 
 func New(token string, opts ...Option) *Client {
 	cli := &Client{
-		pddToken:   token,
+		pddToken:   NewStaticToken(token),
 		clientType: clientTypeAdmin,
+		baseURL:    defaultBaseURL,
 	}
 
 	for _, o := range opts {
@@ -45,7 +68,7 @@ type Option func(*Client)
 func AsRegistrar(oauthToken string) Option {
 	return func(cli *Client) {
 		cli.clientType = clientTypeRegistrar
-		cli.oauthToken = oauthToken
+		cli.oauthToken = NewStaticToken(oauthToken)
 	}
 }
 
@@ -55,23 +78,254 @@ func WithHTTPClient(httpCli *http.Client) Option {
 	}
 }
 
-func (c *Client) getURL(section, action string, params *DNSRequestParams) string {
-	u := fmt.Sprintf("https://pddimp.yandex.ru/api2/%s/%s/%s", c.clientType, section, action)
+// WithBaseURL replaces the default API URL https://pddimp.yandex.ru/api2/.
+// Admin and registrar prefixes and sections are appended to it.
+func WithBaseURL(baseURL string) Option {
+	return func(cli *Client) {
+		cli.baseURL = baseURL
+	}
+}
+
+// WithFallbackURLs sets API URLs which are tried in order when the base URL is unreachable
+func WithFallbackURLs(urls ...string) Option {
+	return func(cli *Client) {
+		cli.fallbackURLs = urls
+	}
+}
+
+func (c *Client) getURL(baseURL, section, action string, params url.Values) string {
+	u := fmt.Sprintf("%s/%s/%s/%s", strings.TrimRight(baseURL, "/"), c.clientType, section, action)
 	if params != nil {
-		u = u + "?" + url.Values(*params).Encode()
+		u = u + "?" + params.Encode()
 	}
 	return u
 }
 
-func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) error {
+// Operation describes a single logical call of PDD API
+type Operation struct {
+	Name    string // name of the client method, e.g. "DNSAdd"
+	Method  string
+	Section string
+	Action  string
+	Domain  string
+	Params  url.Values
+	// Header contains additional HTTP headers sent with the request
+	Header http.Header
+
+	// landed checks whether a mutation which failed with a retryable error
+	// has been applied anyway. On success it fills the result.
+	landed func(ctx context.Context, v interface{}) (bool, error)
+	// simulate validates a mutation and fills the result it would have in dry-run mode
+	simulate func(ctx context.Context, v interface{}) error
+}
+
+func (c *Client) newHTTPRequest(baseURL string, op *Operation) (*http.Request, error) {
+	if op.Method == http.MethodGet {
+		return http.NewRequest(op.Method, c.getURL(baseURL, op.Section, op.Action, op.Params), nil)
+	}
+	params := DNSRequestParams(op.Params)
+	return http.NewRequest(op.Method, c.getURL(baseURL, op.Section, op.Action, nil), params.body())
+}
+
+func (c *Client) do(ctx context.Context, op *Operation, v interface{}) (err error) {
+	if op.Header == nil {
+		op.Header = http.Header{}
+	}
+
+	ctx, span := c.startOperationSpan(ctx, op)
+	defer func() {
+		if c.tracer != nil {
+			span.SetAttributes(resultAttributes(v, err)...)
+		}
+		span.End(err)
+	}()
+
+	h := c.execute
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h(ctx, op, v)
+}
+
+// execute is the last handler of middleware chain which sends the request to PDD
+func (c *Client) execute(ctx context.Context, op *Operation, v interface{}) (err error) {
+	if c.metrics != nil {
+		start := time.Now()
+		defer func() {
+			c.metrics.ObserveCall(op.Section, op.Action, outcome(err), time.Since(start))
+		}()
+	}
+
+	if c.dryRun && op.Method != http.MethodGet {
+		return c.dryRunOperation(ctx, op, v)
+	}
+
+	if c.retry == nil {
+		return c.send(ctx, op, v)
+	}
+	return c.retry.do(ctx, op, v, c.send)
+}
+
+// send makes a single attempt of request trying fallback URLs if necessary
+func (c *Client) send(ctx context.Context, op *Operation, v interface{}) (err error) {
+	// sent is set when a request is passed to the HTTP client, errors before it tell nothing about PDD
+	var sent bool
+	if c.breaker != nil {
+		trial, openErr := c.breaker.allow()
+		if openErr != nil {
+			return openErr
+		}
+		defer func() {
+			c.breaker.done(ctx, trial, sent, err)
+		}()
+	}
+
+	attempt := attemptFrom(ctx)
+	if c.metrics != nil && attempt > 1 {
+		c.metrics.IncRetry(op.Section, op.Action)
+	}
+
+	ctx, span := c.startSpan(ctx, "yapdd.attempt", Attribute{Key: "yapdd.attempt", Value: attempt})
+	defer func() {
+		span.End(err)
+	}()
+
+	err = c.doURL(ctx, c.baseURL, op, v, &sent)
+	for _, u := range c.fallbackURLs {
+		if !isUnreachable(ctx, op, err) {
+			break
+		}
+		err = c.doURL(ctx, u, op, v, &sent)
+	}
+	return err
+}
+
+// isUnreachable reports whether request failed before any response was received
+// and may be sent to another URL. Requests other than GET are resent only when
+// connection could not be established, so a mutation is never applied twice.
+func isUnreachable(ctx context.Context, op *Operation, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+
+	if op.Method == http.MethodGet {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+func (c *Client) doURL(ctx context.Context, baseURL string, op *Operation, v interface{}, sent *bool) (err error) {
+	tokens, err := c.tokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	var limiter *rateLimiter
+	if c.rateLimit != nil {
+		limiter = limiterFor(c.limiterKey(tokens.pddToken), c.rateLimit)
+		wait, err := limiter.wait(ctx)
+		if err != nil {
+			return err
+		}
+		if c.metrics != nil && wait > 0 {
+			c.metrics.ObserveRateLimitWait(op.Section, op.Action, wait)
+		}
+	}
+
+	req, err := c.newHTTPRequest(baseURL, op)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := c.startSpan(ctx, "yapdd.http",
+		Attribute{Key: "http.method", Value: req.Method},
+		Attribute{Key: "http.url", Value: redactURL(req.URL)},
+	)
+	defer func() {
+		span.End(err)
+	}()
+
 	req = req.WithContext(ctx)
+	c.setHeaders(req, op, tokens)
+
+	var ex exchange
+	start := time.Now()
+	*sent = true
+	err = c.roundTrip(req, op, v, &ex)
+	ex.latency = time.Since(start)
+
+	if ex.statusCode != 0 {
+		span.SetAttributes(Attribute{Key: "http.status_code", Value: ex.statusCode})
+	}
+	if limiter != nil {
+		limiter.update(err)
+	}
+	if c.logger != nil {
+		c.logExchange(ctx, op, req, &ex, err)
+	}
+	if c.metrics != nil && ex.status.Success != "" && ex.status.Success != successOK {
+		c.metrics.IncAPIError(op.Section, op.Action, ex.status.code())
+	}
+
+	return err
+}
+
+// requestTokens are tokens taken from token sources for a single request
+type requestTokens struct {
+	pddToken   string
+	oauthToken string
+}
 
+func (c *Client) tokens(ctx context.Context) (*requestTokens, error) {
+	pddToken, err := c.pddToken.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get PDD token: %w", err)
+	}
+
+	t := &requestTokens{pddToken: pddToken}
+	if c.clientType == clientTypeRegistrar {
+		if t.oauthToken, err = c.oauthToken.Token(ctx); err != nil {
+			return nil, fmt.Errorf("can't get OAuth token: %w", err)
+		}
+	}
+
+	return t, nil
+}
+
+func (c *Client) setHeaders(req *http.Request, op *Operation, tokens *requestTokens) {
+	for k, v := range op.Header {
+		req.Header[k] = v
+	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header["PddToken"] = []string{c.pddToken}
+	req.Header["PddToken"] = []string{tokens.pddToken}
 	if c.clientType == clientTypeRegistrar {
-		req.Header.Set("Authorization", "OAuth "+c.oauthToken)
+		req.Header.Set("Authorization", "OAuth "+tokens.oauthToken)
 	}
+}
 
+// exchange holds details of a single HTTP request used for logging
+type exchange struct {
+	latency    time.Duration
+	statusCode int
+	body       []byte
+	status     apiStatus
+	dryRun     bool
+}
+
+// roundTrip sends req and decodes response into v
+func (c *Client) roundTrip(req *http.Request, op *Operation, v interface{}, ex *exchange) error {
 	resp, err := c.httpCli.Do(req)
 	if err != nil {
 		return err
@@ -82,10 +336,32 @@ func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) error
 		resp.Body.Close()
 	}()
 
+	ex.statusCode = resp.StatusCode
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected response status: %s", resp.Status)
+		httpErr := newHTTPError(req, resp)
+		ex.body = httpErr.Body
+		return httpErr
+	}
+
+	ex.body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
 	}
 
-	dec := json.NewDecoder(resp.Body)
-	return dec.Decode(v)
+	if err = json.Unmarshal(ex.body, &ex.status); err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(ex.body, v); err != nil {
+		return err
+	}
+
+	if err = ex.status.err(op); err != nil {
+		return err
+	}
+
+	if c.strict {
+		return c.checkSchema(req.Context(), op, ex.body, v)
+	}
+	return nil
 }
@@ -1,7 +1,15 @@
 package yapdd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"testing"
 )
 
 type httpTransportMock struct {
@@ -14,3 +22,123 @@ func (m *httpTransportMock) RoundTrip(r *http.Request) (*http.Response, error) {
 	m.request = r
 	return m.response, m.err
 }
+
+// hostTransportMock fails requests to hosts listed in errs and records requested URLs
+type hostTransportMock struct {
+	errs     map[string]error
+	body     string
+	requests []string
+}
+
+func (m *hostTransportMock) RoundTrip(r *http.Request) (*http.Response, error) {
+	m.requests = append(m.requests, r.URL.String())
+	if err, ok := m.errs[r.URL.Host]; ok {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(m.body)),
+	}, nil
+}
+
+func TestClient_BaseURL(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}
+
+	cases := []struct {
+		name        string
+		opts        []Option
+		errs        map[string]error
+		call        func(*Client) error
+		expErr      error
+		expRequests []string
+	}{
+		{
+			name: "base url",
+			opts: []Option{WithBaseURL("http://127.0.0.1:8080/api2")},
+			call: func(c *Client) error {
+				_, err := c.DNSList(context.Background(), "domain.com")
+				return err
+			},
+			expRequests: []string{
+				"http://127.0.0.1:8080/api2/admin/dns/list?domain=domain.com",
+			},
+		},
+		{
+			name: "base url with registrar prefix",
+			opts: []Option{WithBaseURL("http://127.0.0.1:8080/api2/"), AsRegistrar("oauth")},
+			call: func(c *Client) error {
+				_, err := c.DNSDel(context.Background(), "domain.com", 1)
+				return err
+			},
+			expRequests: []string{
+				"http://127.0.0.1:8080/api2/registrar/dns/del",
+			},
+		},
+		{
+			name: "fallback on unreachable url",
+			opts: []Option{WithFallbackURLs("http://first/api2/", "http://second/api2/")},
+			errs: map[string]error{
+				"pddimp.yandex.ru": dialErr,
+				"first":            errors.New("fail"),
+			},
+			call: func(c *Client) error {
+				_, err := c.DNSList(context.Background(), "domain.com")
+				return err
+			},
+			expRequests: []string{
+				"https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com",
+				"http://first/api2/admin/dns/list?domain=domain.com",
+				"http://second/api2/admin/dns/list?domain=domain.com",
+			},
+		},
+		{
+			name: "mutation is not resent after connection was established",
+			opts: []Option{WithFallbackURLs("http://first/api2/")},
+			errs: map[string]error{
+				"pddimp.yandex.ru": readErr,
+			},
+			call: func(c *Client) error {
+				_, err := c.DNSDel(context.Background(), "domain.com", 1)
+				return err
+			},
+			expErr: &url.Error{Op: "Post", URL: "https://pddimp.yandex.ru/api2/admin/dns/del", Err: readErr},
+			expRequests: []string{
+				"https://pddimp.yandex.ru/api2/admin/dns/del",
+			},
+		},
+		{
+			name: "mutation is resent when url is unreachable",
+			opts: []Option{WithFallbackURLs("http://first/api2/")},
+			errs: map[string]error{
+				"pddimp.yandex.ru": dialErr,
+			},
+			call: func(c *Client) error {
+				_, err := c.DNSDel(context.Background(), "domain.com", 1)
+				return err
+			},
+			expRequests: []string{
+				"https://pddimp.yandex.ru/api2/admin/dns/del",
+				"http://first/api2/admin/dns/del",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &hostTransportMock{
+				errs: tc.errs,
+				body: `{"success": "ok"}`,
+			}
+
+			opts := append([]Option{WithHTTPClient(&http.Client{Transport: transport})}, tc.opts...)
+			err := tc.call(New("token", opts...))
+			if fmt.Sprint(tc.expErr) != fmt.Sprint(err) {
+				t.Errorf("expected error: %v, got: %v", tc.expErr, err)
+			}
+			if fmt.Sprint(tc.expRequests) != fmt.Sprint(transport.requests) {
+				t.Errorf("expected requests: %v, got: %v", tc.expRequests, transport.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+// Package cassette provides http.RoundTripper recording exchanges with PDD API
+// to a file and replaying them in tests
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mode defines whether a cassette records or replays interactions
+type Mode int
+
+const (
+	// ModeReplay answers requests with interactions loaded from the cassette file
+	ModeReplay Mode = iota
+	// ModeRecord sends requests with the underlying transport and records interactions
+	ModeRecord
+)
+
+const redacted = "REDACTED"
+
+// ErrUnmatched is returned by RoundTrip in replay mode when no recorded interaction matches a request
+var ErrUnmatched = errors.New("cassette: no recorded interaction matches request")
+
+var (
+	secretHeaders = []string{"PddToken", "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	secretParams  = []string{"token", "pddtoken", "oauth_token", "password", "passwd"}
+
+	secretJSONField = regexp.MustCompile(`(?i)("[^"]*(?:token|passw)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+// Interaction is a recorded request and the response to it
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Query and form body are normalized: parameters are sorted
+// and secrets are redacted.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Form   string      `json:"form,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Cassette records or replays HTTP interactions
+type Cassette struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	t         TestingT
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// Option configures a cassette
+type Option func(*Cassette)
+
+// WithTransport sets the transport used in record mode, http.DefaultTransport by default
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Cassette) {
+		c.transport = rt
+	}
+}
+
+// TestingT is the part of testing.TB used by the cassette
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// WithT makes the cassette report unmatched requests as test errors in addition to returning ErrUnmatched
+func WithT(t TestingT) Option {
+	return func(c *Cassette) {
+		c.t = t
+	}
+}
+
+// New returns a cassette stored in file path. In replay mode the file is loaded immediately.
+func New(path string, mode Mode, opts ...Option) (*Cassette, error) {
+	c := &Cassette{
+		path: path,
+		mode: mode,
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	if c.transport == nil {
+		c.transport = http.DefaultTransport
+	}
+
+	if mode == ModeReplay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &c.interactions); err != nil {
+			return nil, fmt.Errorf("cassette: can't decode %s: %s", path, err)
+		}
+		c.used = make([]bool, len(c.interactions))
+	}
+
+	return c, nil
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	r, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.mode == ModeRecord {
+		return c.record(req, r)
+	}
+	return c.replay(req, r)
+}
+
+func (c *Cassette) record(req *http.Request, r *Request) (*http.Response, error) {
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, &Interaction{
+		Request: *r,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header, secrets(req.Header)),
+			Body:       redactBody(string(body), secrets(req.Header)),
+		},
+	})
+
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, r *Request) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, in := range c.interactions {
+		if c.used[i] || !in.Request.matches(r) {
+			continue
+		}
+
+		c.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header,
+			Body:          ioutil.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	err := fmt.Errorf("%w: %s %s query %q form %q", ErrUnmatched, r.Method, r.Path, r.Query, r.Form)
+	if c.t != nil {
+		c.t.Errorf("%s", err)
+	}
+	return nil, err
+}
+
+// Save writes recorded interactions to the cassette file
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, append(data, '\n'), 0644)
+}
+
+// Unused returns interactions which have not been replayed yet
+func (c *Cassette) Unused() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var unused []*Interaction
+	for i, in := range c.interactions {
+		if !c.used[i] {
+			unused = append(unused, in)
+		}
+	}
+	return unused
+}
+
+func newRequest(req *http.Request) (*Request, error) {
+	r := &Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  normalize(req.URL.Query()),
+		Header: redactHeader(req.Header, nil),
+	}
+
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("cassette: can't parse request body: %s", err)
+		}
+		r.Form = normalize(form)
+	}
+
+	return r, nil
+}
+
+func (r *Request) matches(other *Request) bool {
+	return r.Method == other.Method &&
+		r.Path == other.Path &&
+		r.Query == other.Query &&
+		r.Form == other.Form
+}
+
+// normalize encodes parameters sorted by key with secrets redacted
+func normalize(values url.Values) string {
+	for k := range values {
+		for _, s := range secretParams {
+			if strings.EqualFold(k, s) {
+				values[k] = []string{redacted}
+			}
+		}
+	}
+	return values.Encode()
+}
+
+// redactHeader returns a copy of h with secret headers and secrets in other headers redacted
+func redactHeader(h http.Header, secrets []string) http.Header {
+	r := http.Header{}
+	for k, v := range h {
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = redactBody(v[i], secrets)
+		}
+		r[k] = values
+
+		for _, s := range secretHeaders {
+			if strings.EqualFold(k, s) {
+				r[k] = []string{redacted}
+			}
+		}
+	}
+	return r
+}
+
+// secrets returns values of secret headers
+func secrets(h http.Header) []string {
+	var values []string
+	for k, v := range h {
+		for _, s := range secretHeaders {
+			if !strings.EqualFold(k, s) {
+				continue
+			}
+			for _, value := range v {
+				if i := strings.IndexByte(value, ' '); i >= 0 {
+					value = value[i+1:]
+				}
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func redactBody(body string, secrets []string) string {
+	body = secretJSONField.ReplaceAllString(body, `$1"`+redacted+`"`)
+	for _, s := range secrets {
+		if s != "" {
+			body = strings.Replace(body, s, redacted, -1)
+		}
+	}
+	return body
+}
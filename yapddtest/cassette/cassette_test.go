@@ -0,0 +1,133 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/reinventer/yapdd"
+)
+
+type transportMock struct {
+	requests int
+}
+
+func (m *transportMock) RoundTrip(r *http.Request) (*http.Response, error) {
+	m.requests++
+	body := `{"domain": "domain.com", "records": [], "success": "ok"}`
+	if r.Method == http.MethodPost {
+		body = `{"domain": "domain.com", "record": {"record_id": 1, "type": "TXT", "content": "secret-token"}, "token": "abc", "success": "ok"}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type": {"application/json"},
+			"Set-Cookie":   {"session=s3cr3t; HttpOnly"},
+			"X-Echo":       {"token secret-token"},
+		},
+		Body: ioutil.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatalf("can't create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "dns.json")
+
+	transport := &transportMock{}
+	recorder, err := New(path, ModeRecord, WithTransport(transport))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	recorded := exercise(t, recorder)
+	if err = recorder.Save(); err != nil {
+		t.Fatalf("can't save cassette: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("can't read cassette: %s", err)
+	}
+	if strings.Contains(string(data), "secret-token") || strings.Contains(string(data), `"abc"`) ||
+		strings.Contains(string(data), "s3cr3t") {
+		t.Errorf("expected secrets to be redacted in cassette:\n%s", data)
+	}
+	if !strings.Contains(string(data), "application/json") {
+		t.Errorf("expected other response headers to be recorded:\n%s", data)
+	}
+
+	player, err := New(path, ModeReplay, WithT(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	replayed := exercise(t, player)
+	if transport.requests != 2 {
+		t.Errorf("expected 2 requests sent while recording, got: %d", transport.requests)
+	}
+	if !reflect.DeepEqual(recorded[0], replayed[0]) {
+		t.Errorf("expected replayed list response: %+v, got: %+v", recorded[0], replayed[0])
+	}
+	if resp := replayed[1].(*yapdd.DNSResponse); resp.Record.ID != 1 || resp.Record.Content != "REDACTED" {
+		t.Errorf("unexpected replayed add response: %+v", resp.Record)
+	}
+	if unused := player.Unused(); len(unused) != 0 {
+		t.Errorf("expected all interactions to be replayed, unused: %d", len(unused))
+	}
+}
+
+// exercise makes the same calls with a token different in every run
+func exercise(t *testing.T, rt http.RoundTripper) []interface{} {
+	cli := yapdd.New("secret-token", yapdd.WithHTTPClient(&http.Client{Transport: rt}))
+
+	list, err := cli.DNSList(context.Background(), "domain.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	add, err := cli.DNSAdd(context.Background(), "domain.com", yapdd.DNSTypeTXT, yapdd.NewDNSParams().Content("text").TTL(900))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return []interface{}{list, add}
+}
+
+func TestCassette_Unmatched(t *testing.T) {
+	c := &Cassette{
+		mode: ModeReplay,
+		interactions: []*Interaction{
+			{
+				Request:  Request{Method: http.MethodPost, Path: "/api2/admin/dns/add", Form: "content=a&domain=domain.com"},
+				Response: Response{StatusCode: http.StatusOK, Body: `{"success": "ok"}`},
+			},
+		},
+		used: []bool{false},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://pddimp.yandex.ru/api2/admin/dns/add", strings.NewReader("domain=domain.com&content=a"))
+	if err != nil {
+		t.Fatalf("can't create request: %s", err)
+	}
+	if _, err = c.RoundTrip(req); err != nil {
+		t.Errorf("expected request with reordered form to match, got: %s", err)
+	}
+
+	req, err = http.NewRequest(http.MethodPost, "https://pddimp.yandex.ru/api2/admin/dns/add", strings.NewReader("domain=domain.com&content=a"))
+	if err != nil {
+		t.Fatalf("can't create request: %s", err)
+	}
+	if _, err = c.RoundTrip(req); !errors.Is(err, ErrUnmatched) {
+		t.Errorf("expected error: %v, got: %v", ErrUnmatched, err)
+	}
+}
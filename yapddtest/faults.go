@@ -0,0 +1,186 @@
+package yapddtest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/reinventer/yapdd"
+)
+
+// Fault handles a request instead of the transport. It may call next to pass the request through.
+type Fault func(req *http.Request, next http.RoundTripper) (*http.Response, error)
+
+// ProbableFault is a fault happening with the given probability
+type ProbableFault struct {
+	Probability float64
+	Fault       Fault
+}
+
+// FaultyTransport wraps Base injecting faults into requests.
+// Faults from Script are applied to requests one by one in order. When the script is exhausted,
+// every request gets one of Random faults chosen by probability or passes through unchanged.
+type FaultyTransport struct {
+	Base   http.RoundTripper // http.DefaultTransport if nil
+	Script []Fault
+	Random []ProbableFault
+	Rand   *rand.Rand // a generator with seed 1 if nil, so runs are reproducible
+
+	mu       sync.Mutex
+	requests int
+}
+
+func (t *FaultyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return t.next()(req, base)
+}
+
+// Requests returns the number of requests made through the transport
+func (t *FaultyTransport) Requests() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.requests
+}
+
+func (t *FaultyTransport) next() Fault {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.requests++
+	if len(t.Script) > 0 {
+		f := t.Script[0]
+		t.Script = t.Script[1:]
+		return f
+	}
+
+	if len(t.Random) == 0 {
+		return Pass()
+	}
+
+	if t.Rand == nil {
+		t.Rand = rand.New(rand.NewSource(1))
+	}
+
+	p := t.Rand.Float64()
+	for _, f := range t.Random {
+		if p < f.Probability {
+			return f.Fault
+		}
+		p -= f.Probability
+	}
+	return Pass()
+}
+
+// Pass sends the request unchanged
+func Pass() Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		return next.RoundTrip(req)
+	}
+}
+
+// Latency delays the request by d or until its context is done
+func Latency(d time.Duration) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		t := time.NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+			return next.RoundTrip(req)
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// Reset fails the request with connection reset before it reaches the server
+func Reset() Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		return nil, resetError()
+	}
+}
+
+// ResetAfterDelivery passes the request to the server but loses the response with connection reset
+func ResetAfterDelivery() Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, resetError()
+	}
+}
+
+func resetError() error {
+	return &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+}
+
+// Status answers with HTTP status code without sending the request.
+// Status 429 is answered with "Retry-After: 1".
+func Status(code int) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		header := http.Header{"Content-Type": {"text/html"}}
+		if code == http.StatusTooManyRequests {
+			header.Set("Retry-After", "1")
+		}
+		return response(req, code, header, http.StatusText(code)), nil
+	}
+}
+
+// TruncatedJSON passes the request to the server and cuts the response body in half
+func TruncatedJSON() Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		body = body[:len(body)/2]
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		resp.Header.Del("Content-Length")
+		return resp, nil
+	}
+}
+
+// APIError answers with {"success": "error"} and code without sending the request
+func APIError(code yapdd.ErrorCode) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		body := fmt.Sprintf(`{"success": "error", "error": %q}`, code)
+		return response(req, http.StatusOK, http.Header{"Content-Type": {"application/json"}}, body), nil
+	}
+}
+
+func response(req *http.Request, code int, header http.Header, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
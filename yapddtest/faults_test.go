@@ -0,0 +1,91 @@
+package yapddtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/reinventer/yapdd"
+)
+
+func TestFaultyTransport_Script(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDomain("token", "domain.com")
+
+	transport := &FaultyTransport{
+		Base: srv.Server.Client().Transport,
+		Script: []Fault{
+			Reset(),
+			Status(http.StatusServiceUnavailable),
+			TruncatedJSON(),
+			APIError(yapdd.ErrNoAuth),
+			Latency(time.Millisecond),
+		},
+	}
+	cli := srv.Client("token", yapdd.WithHTTPClient(&http.Client{Transport: transport}))
+	ctx := context.Background()
+
+	_, err := cli.DNSList(ctx, "domain.com")
+	if !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("expected connection reset, got: %v", err)
+	}
+
+	_, err = cli.DNSList(ctx, "domain.com")
+	var httpErr *yapdd.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected http error 503, got: %v", err)
+	}
+
+	if _, err = cli.DNSList(ctx, "domain.com"); err == nil {
+		t.Errorf("expected error decoding truncated json")
+	}
+
+	if _, err = cli.DNSList(ctx, "domain.com"); !errors.Is(err, yapdd.ErrNoAuth) {
+		t.Errorf("expected api error no_auth, got: %v", err)
+	}
+
+	if _, err = cli.DNSList(ctx, "domain.com"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if transport.Requests() != 5 {
+		t.Errorf("expected 5 requests, got: %d", transport.Requests())
+	}
+}
+
+func TestFaultyTransport_Random(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDomain("token", "domain.com")
+
+	transport := &FaultyTransport{
+		Base: srv.Server.Client().Transport,
+		Random: []ProbableFault{
+			{Probability: 0.3, Fault: Status(http.StatusBadGateway)},
+			{Probability: 0.2, Fault: ResetAfterDelivery()},
+		},
+	}
+	cli := srv.Client(
+		"token",
+		yapdd.WithHTTPClient(&http.Client{Transport: transport}),
+		yapdd.WithRetry(yapdd.RetryPolicy{MaxAttempts: 10, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Mutations: true}),
+	)
+
+	// retries of mutations must never create duplicates even when responses are lost
+	for i := 0; i < 20; i++ {
+		if _, err := cli.DNSAdd(context.Background(), "domain.com", yapdd.DNSTypeTXT, yapdd.NewDNSParams().Subdomain("s").Content(string(rune('a'+i)))); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if records := srv.Records("domain.com"); len(records) != 20 {
+		t.Errorf("expected 20 records, got: %d", len(records))
+	}
+	if transport.Requests() <= 20 {
+		t.Errorf("expected faults to cause extra requests, got: %d", transport.Requests())
+	}
+}
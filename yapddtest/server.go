@@ -0,0 +1,420 @@
+// Package yapddtest provides helpers for testing code built on yapdd,
+// such as a stateful fake of PDD API and a fault injecting transport
+package yapddtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/reinventer/yapdd"
+)
+
+const defaultTTL = 21600
+
+// handler implements an action of a section, it returns the response or an error code
+type handler func(s *Server, acc *account, r *http.Request) (interface{}, yapdd.ErrorCode)
+
+// handlers maps "section/action" to the handler and the HTTP method it accepts
+var handlers = map[string]struct {
+	method string
+	h      handler
+}{
+	"dns/add":  {http.MethodPost, (*Server).dnsAdd},
+	"dns/list": {http.MethodGet, (*Server).dnsList},
+	"dns/edit": {http.MethodPost, (*Server).dnsEdit},
+	"dns/del":  {http.MethodPost, (*Server).dnsDel},
+
+	"domain/domains": {http.MethodGet, (*Server).domainDomains},
+}
+
+// Server is an in-memory fake of PDD API served by httptest.Server.
+// It supports admin and registrar prefixes and checks PddToken and OAuth headers.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	accounts map[string]*account // by PDD token
+	zones    map[string]*zone    // by domain
+	nextID   uint32
+}
+
+type account struct {
+	token      string
+	oauthToken string // set for registrar accounts
+	domains    map[string]bool
+}
+
+type zone struct {
+	domain  string
+	records []*record
+}
+
+// record has the same JSON shape as a record returned by PDD
+type record struct {
+	ID        uint32      `json:"record_id"`
+	Type      string      `json:"type"`
+	Domain    string      `json:"domain"`
+	Subdomain string      `json:"subdomain"`
+	FQDN      string      `json:"fqdn"`
+	TTL       uint32      `json:"ttl"`
+	Content   string      `json:"content"`
+	Priority  interface{} `json:"priority"`
+	Weight    interface{} `json:"weight,omitempty"`
+	Port      interface{} `json:"port,omitempty"`
+	Operation string      `json:"operation,omitempty"`
+	AdminMail string      `json:"admin_mail,omitempty"`
+	Refresh   uint32      `json:"refresh,omitempty"`
+	Retry     uint32      `json:"retry,omitempty"`
+	Expire    uint32      `json:"expire,omitempty"`
+	MinTTL    uint32      `json:"minttl,omitempty"`
+}
+
+// NewServer starts a fake PDD server. It should be closed when the test finishes.
+func NewServer() *Server {
+	s := &Server{
+		accounts: map[string]*account{},
+		zones:    map[string]*zone{},
+		nextID:   1,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddDomain registers domain owned by the account with PDD token
+func (s *Server) AddDomain(token, domain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc := s.account(token)
+	domain = strings.ToLower(domain)
+	acc.domains[domain] = true
+	if _, ok := s.zones[domain]; !ok {
+		s.zones[domain] = &zone{domain: domain}
+	}
+}
+
+// SetRegistrar makes the account with PDD token a registrar account accepting oauthToken
+func (s *Server) SetRegistrar(token, oauthToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.account(token).oauthToken = oauthToken
+}
+
+// Client returns yapdd client sending requests to the server
+func (s *Server) Client(token string, opts ...yapdd.Option) *yapdd.Client {
+	opts = append([]yapdd.Option{
+		yapdd.WithHTTPClient(s.Server.Client()),
+		yapdd.WithBaseURL(s.URL + "/api2/"),
+	}, opts...)
+	return yapdd.New(token, opts...)
+}
+
+// Records returns copies of records of domain in the PDD JSON shape
+func (s *Server) Records(domain string) []yapdd.DNSRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z, ok := s.zones[strings.ToLower(domain)]
+	if !ok {
+		return nil
+	}
+
+	data, _ := json.Marshal(z.records)
+	var records []yapdd.DNSRecord
+	json.Unmarshal(data, &records)
+	return records
+}
+
+func (s *Server) account(token string) *account {
+	acc, ok := s.accounts[token]
+	if !ok {
+		acc = &account{token: token, domains: map[string]bool{}}
+		s.accounts[token] = acc
+	}
+	return acc
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// path is /api2/{admin|registrar}/{section}/{action}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "api2" || (parts[1] != "admin" && parts[1] != "registrar") {
+		http.NotFound(w, r)
+		return
+	}
+
+	h, ok := handlers[parts[2]+"/"+parts[3]]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != h.method {
+		w.Header().Set("Allow", h.method)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, code := s.authorize(r, parts[1] == "registrar")
+	var resp interface{}
+	if code == "" {
+		resp, code = h.h(s, acc, r)
+	}
+
+	if code != "" {
+		resp = map[string]string{"success": "error", "error": string(code)}
+		if d := r.Form.Get("domain"); d != "" {
+			resp.(map[string]string)["domain"] = d
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) authorize(r *http.Request, registrar bool) (*account, yapdd.ErrorCode) {
+	token := r.Header.Get("PddToken")
+	if token == "" {
+		return nil, yapdd.ErrNoAuth
+	}
+
+	acc, ok := s.accounts[token]
+	if !ok {
+		return nil, yapdd.ErrBadToken
+	}
+
+	if registrar {
+		auth := r.Header.Get("Authorization")
+		if acc.oauthToken == "" || auth != "OAuth "+acc.oauthToken {
+			return nil, yapdd.ErrBadToken
+		}
+	}
+
+	return acc, ""
+}
+
+// zone returns the zone of domain from the request if the account owns it
+func (s *Server) zone(acc *account, r *http.Request) (*zone, yapdd.ErrorCode) {
+	domain := strings.ToLower(r.Form.Get("domain"))
+	if domain == "" {
+		return nil, yapdd.ErrNoDomain
+	}
+	if !strings.Contains(domain, ".") {
+		return nil, yapdd.ErrBadDomain
+	}
+	if !acc.domains[domain] {
+		return nil, yapdd.ErrNotAllowed
+	}
+	return s.zones[domain], ""
+}
+
+func (z *zone) find(r *http.Request) (int, yapdd.ErrorCode) {
+	id, err := strconv.ParseUint(r.Form.Get("record_id"), 10, 32)
+	if err != nil {
+		return 0, "no_record_id"
+	}
+	for i, rec := range z.records {
+		if rec.ID == uint32(id) {
+			return i, ""
+		}
+	}
+	return 0, yapdd.ErrNotFound
+}
+
+func (s *Server) dnsAdd(acc *account, r *http.Request) (interface{}, yapdd.ErrorCode) {
+	z, code := s.zone(acc, r)
+	if code != "" {
+		return nil, code
+	}
+
+	t := strings.ToUpper(r.Form.Get("type"))
+	switch yapdd.DNSRecordType(t) {
+	case yapdd.DNSTypeA, yapdd.DNSTypeAAAA, yapdd.DNSTypeCNAME, yapdd.DNSTypeMX,
+		yapdd.DNSTypeNS, yapdd.DNSTypeSOA, yapdd.DNSTypeSRV, yapdd.DNSTypeTXT:
+	case "":
+		return nil, "no_type"
+	default:
+		return nil, "bad_type"
+	}
+
+	rec := &record{
+		ID:        s.nextID,
+		Type:      t,
+		Domain:    z.domain,
+		Subdomain: "@",
+		TTL:       defaultTTL,
+		Priority:  "",
+	}
+	if code = rec.apply(r); code != "" {
+		return nil, code
+	}
+	if rec.Content == "" {
+		return nil, "no_content"
+	}
+
+	s.nextID++
+	z.records = append(z.records, rec)
+
+	return map[string]interface{}{
+		"domain":  z.domain,
+		"record":  rec,
+		"success": "ok",
+	}, ""
+}
+
+func (s *Server) dnsList(acc *account, r *http.Request) (interface{}, yapdd.ErrorCode) {
+	z, code := s.zone(acc, r)
+	if code != "" {
+		return nil, code
+	}
+
+	records := append([]*record{}, z.records...)
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	return map[string]interface{}{
+		"domain":  z.domain,
+		"records": records,
+		"success": "ok",
+	}, ""
+}
+
+func (s *Server) dnsEdit(acc *account, r *http.Request) (interface{}, yapdd.ErrorCode) {
+	z, code := s.zone(acc, r)
+	if code != "" {
+		return nil, code
+	}
+
+	i, code := z.find(r)
+	if code != "" {
+		return nil, code
+	}
+
+	edited := *z.records[i]
+	if code = edited.apply(r); code != "" {
+		return nil, code
+	}
+	z.records[i] = &edited
+
+	resp := edited
+	resp.Operation = "editing"
+	return map[string]interface{}{
+		"domain":  z.domain,
+		"record":  &resp,
+		"success": "ok",
+	}, ""
+}
+
+func (s *Server) dnsDel(acc *account, r *http.Request) (interface{}, yapdd.ErrorCode) {
+	z, code := s.zone(acc, r)
+	if code != "" {
+		return nil, code
+	}
+
+	i, code := z.find(r)
+	if code != "" {
+		return nil, code
+	}
+
+	id := z.records[i].ID
+	z.records = append(z.records[:i], z.records[i+1:]...)
+
+	return map[string]interface{}{
+		"domain":    z.domain,
+		"record_id": id,
+		"success":   "ok",
+	}, ""
+}
+
+func (s *Server) domainDomains(acc *account, r *http.Request) (interface{}, yapdd.ErrorCode) {
+	page, onPage := 1, 10
+	if v, err := strconv.Atoi(r.Form.Get("page")); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(r.Form.Get("on_page")); err == nil && v > 0 {
+		onPage = v
+	}
+
+	names := make([]string, 0, len(acc.domains))
+	for d := range acc.domains {
+		names = append(names, d)
+	}
+	sort.Strings(names)
+
+	domains := []map[string]interface{}{}
+	for i := (page - 1) * onPage; i < len(names) && i < page*onPage; i++ {
+		domains = append(domains, map[string]interface{}{
+			"name":        names[i],
+			"status":      "added",
+			"nsdelegated": true,
+			"aliases":     []string{},
+		})
+	}
+
+	return map[string]interface{}{
+		"page":    page,
+		"on_page": onPage,
+		"total":   len(names),
+		"found":   len(names),
+		"domains": domains,
+		"success": "ok",
+	}, ""
+}
+
+// apply sets record fields to parameters of the request
+func (rec *record) apply(r *http.Request) yapdd.ErrorCode {
+	if _, ok := r.Form["subdomain"]; ok {
+		rec.Subdomain = strings.ToLower(strings.TrimSuffix(r.Form.Get("subdomain"), "."))
+		if rec.Subdomain == "" {
+			rec.Subdomain = "@"
+		}
+	}
+	if v, ok := r.Form["content"]; ok {
+		rec.Content = v[0]
+	}
+	if v, ok := r.Form["target"]; ok && rec.Type == string(yapdd.DNSTypeSRV) {
+		rec.Content = v[0]
+	}
+	if v, ok := r.Form["admin_mail"]; ok && rec.Type == string(yapdd.DNSTypeSOA) {
+		rec.AdminMail = v[0]
+	}
+
+	for _, p := range []struct {
+		name string
+		set  func(uint64)
+	}{
+		{"ttl", func(v uint64) { rec.TTL = uint32(v) }},
+		{"priority", func(v uint64) { rec.Priority = v }},
+		{"weight", func(v uint64) { rec.Weight = v }},
+		{"port", func(v uint64) { rec.Port = v }},
+		{"refresh", func(v uint64) { rec.Refresh = uint32(v) }},
+		{"retry", func(v uint64) { rec.Retry = uint32(v) }},
+		{"expire", func(v uint64) { rec.Expire = uint32(v) }},
+		{"neg_cache", func(v uint64) { rec.MinTTL = uint32(v) }},
+	} {
+		if v, ok := r.Form[p.name]; ok {
+			n, err := strconv.ParseUint(v[0], 10, 32)
+			if err != nil {
+				return yapdd.ErrorCode("bad_" + p.name)
+			}
+			p.set(n)
+		}
+	}
+
+	rec.FQDN = rec.Domain
+	if rec.Subdomain != "@" {
+		rec.FQDN = rec.Subdomain + "." + rec.Domain
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package yapddtest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/reinventer/yapdd"
+)
+
+func TestServer_DNS(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDomain("token", "domain.com")
+
+	ctx := context.Background()
+	cli := srv.Client("token")
+
+	added, err := cli.DNSAdd(ctx, "domain.com", yapdd.DNSTypeA, yapdd.NewDNSParams().Subdomain("www").Content("1.2.3.4"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if added.Record.ID != 1 || added.Record.FQDN != "www.domain.com" || added.Record.TTL != defaultTTL {
+		t.Errorf("unexpected added record: %+v", added.Record)
+	}
+
+	mx, err := cli.DNSAdd(ctx, "domain.com", yapdd.DNSTypeMX, yapdd.NewDNSParams().Content("mx.domain.com").Priority(10))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if p, ok := mx.Record.Priority.Get(); !ok || p != 10 || mx.Record.FQDN != "domain.com" {
+		t.Errorf("unexpected added record: %+v", mx.Record)
+	}
+
+	edited, err := cli.DNSEdit(ctx, "domain.com", 1, yapdd.NewDNSParams().Content("4.3.2.1").TTL(900))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if edited.Record.Content != "4.3.2.1" || edited.Record.TTL != 900 || edited.Record.Operation != "editing" {
+		t.Errorf("unexpected edited record: %+v", edited.Record)
+	}
+
+	if _, err = cli.DNSDel(ctx, "domain.com", 2); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	list, err := cli.DNSList(ctx, "domain.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(list.Records) != 1 || list.Records[0].ID != 1 || list.Records[0].Content != "4.3.2.1" {
+		t.Errorf("unexpected records: %+v", list.Records)
+	}
+
+	if records := srv.Records("domain.com"); len(records) != 1 || records[0].Subdomain != "www" {
+		t.Errorf("unexpected server records: %+v", records)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDomain("token", "domain.com")
+	srv.AddDomain("other", "other.com")
+	srv.SetRegistrar("registrar", "oauth")
+	srv.AddDomain("registrar", "registrar.com")
+
+	ctx := context.Background()
+
+	cases := []struct {
+		name    string
+		cli     *yapdd.Client
+		domain  string
+		expCode yapdd.ErrorCode
+	}{
+		{name: "no token", cli: srv.Client(""), domain: "domain.com", expCode: yapdd.ErrNoAuth},
+		{name: "bad token", cli: srv.Client("bad"), domain: "domain.com", expCode: yapdd.ErrBadToken},
+		{name: "foreign domain", cli: srv.Client("token"), domain: "other.com", expCode: yapdd.ErrNotAllowed},
+		{name: "bad domain", cli: srv.Client("token"), domain: "domain", expCode: yapdd.ErrBadDomain},
+		{name: "registrar without oauth", cli: srv.Client("token", yapdd.AsRegistrar("")), domain: "domain.com", expCode: yapdd.ErrBadToken},
+		{name: "registrar with bad oauth", cli: srv.Client("registrar", yapdd.AsRegistrar("bad")), domain: "registrar.com", expCode: yapdd.ErrBadToken},
+		{name: "registrar", cli: srv.Client("registrar", yapdd.AsRegistrar("oauth")), domain: "registrar.com"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.cli.DNSList(ctx, tc.domain)
+			if tc.expCode == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.expCode) {
+				t.Errorf("expected error code: %s, got: %v", tc.expCode, err)
+			}
+		})
+	}
+
+	_, err := srv.Client("token").DNSEdit(ctx, "domain.com", 42, yapdd.NewDNSParams().Content("1.2.3.4"))
+	if !yapdd.IsNotFound(err) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestServer_DomainList(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDomain("token", "b.com")
+	srv.AddDomain("token", "a.com")
+	srv.AddDomain("token", "c.com")
+	srv.AddDomain("other", "other.com")
+
+	r, err := srv.Client("token").DomainList(context.Background(), 2, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if r.Found != 3 || len(r.Domains) != 1 || r.Domains[0].Name != "c.com" {
+		t.Errorf("unexpected response: %+v", r)
+	}
+}
+
+func TestServer_TypedRecords(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDomain("token", "domain.com")
+	cli := srv.Client("token")
+	ctx := context.Background()
+
+	records := []yapdd.Record{
+		yapdd.ARecord{Subdomain: "www", IP: net.ParseIP("127.0.0.1"), TTL: 900},
+		yapdd.MXRecord{Subdomain: "@", Priority: 10, Exchange: "mx.yandex.net.", TTL: 21600},
+		yapdd.SRVRecord{Service: "sip", Proto: "udp", Priority: 20, Weight: 5, Port: 5060, Target: "sip.domain.com.", TTL: 21600},
+	}
+	for _, rec := range records {
+		if _, err := cli.DNSAddRecord(ctx, "domain.com", rec); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	list, err := cli.DNSList(ctx, "domain.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i, rec := range list.Records {
+		typed, err := rec.Typed()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(records[i], typed) {
+			t.Errorf("expected record: %+v, got: %+v", records[i], typed)
+		}
+	}
+}
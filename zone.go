@@ -0,0 +1,186 @@
+package yapdd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxTXTChunk is the maximum length of a single character string of TXT record
+const maxTXTChunk = 255
+
+// ExportZone writes records of domain returned by DNSList as RFC 1035 master file.
+// PDD does not return the serial of SOA record, so serial 1 is written.
+func (c *Client) ExportZone(ctx context.Context, domain string, w io.Writer) error {
+	list, err := c.DNSList(ctx, domain)
+	if err != nil {
+		return err
+	}
+	return writeZone(w, domain, list.Records)
+}
+
+func (m *MultiClient) ExportZone(ctx context.Context, domain string, w io.Writer) error {
+	cli, err := m.Client(domain)
+	if err != nil {
+		return err
+	}
+	return cli.ExportZone(ctx, domain, w)
+}
+
+func writeZone(w io.Writer, domain string, records []*DNSRecord) error {
+	origin := strings.ToLower(strings.TrimSuffix(domain, ".")) + "."
+	defaultTTL := zoneTTL(records)
+
+	sorted := append([]*DNSRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return zoneOrder(sorted[i]) < zoneOrder(sorted[j])
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "$ORIGIN %s\n$TTL %d\n", origin, defaultTTL)
+
+	tw := tabwriter.NewWriter(bw, 0, 8, 1, '\t', 0)
+	for _, rec := range sorted {
+		rdata, err := zoneRData(rec)
+		if err != nil {
+			return err
+		}
+
+		ttl := ""
+		if rec.TTL != 0 && rec.TTL != defaultTTL {
+			ttl = strconv.FormatUint(uint64(rec.TTL), 10)
+		}
+		fmt.Fprintf(tw, "%s\t%s\tIN\t%s\t%s\n", normalizeSubdomain(rec.Subdomain), ttl, rec.Type, rdata)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// zoneTTL returns the most common TTL of records to be used as $TTL
+func zoneTTL(records []*DNSRecord) uint32 {
+	counts := map[uint32]int{}
+	best := uint32(defaultDNSTTL)
+	for _, rec := range records {
+		if rec.TTL == 0 {
+			continue
+		}
+		counts[rec.TTL]++
+		if n := counts[rec.TTL]; n > counts[best] || n == counts[best] && rec.TTL < best {
+			best = rec.TTL
+		}
+	}
+	return best
+}
+
+// zoneOrder sorts SOA first, then NS records of the domain, then all records by name and type
+func zoneOrder(rec *DNSRecord) string {
+	name := normalizeSubdomain(rec.Subdomain)
+	switch {
+	case rec.Type == DNSTypeSOA:
+		return "0"
+	case rec.Type == DNSTypeNS && name == "@":
+		return "1 " + rec.Content
+	case name == "@":
+		return "2 " + string(rec.Type) + " " + rec.Content
+	}
+
+	// labels are reversed so that records of a subdomain follow the subdomain itself
+	labels := strings.Split(name, ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return "3 " + strings.Join(labels, ".") + " " + string(rec.Type) + " " + rec.Content
+}
+
+func zoneRData(rec *DNSRecord) (string, error) {
+	switch rec.Type {
+	case DNSTypeA, DNSTypeAAAA:
+		return rec.Content, nil
+	case DNSTypeCNAME, DNSTypeNS:
+		return absoluteName(rec.Content), nil
+	case DNSTypeMX:
+		priority, _ := rec.Priority.Get()
+		return fmt.Sprintf("%d %s", priority, absoluteName(rec.Content)), nil
+	case DNSTypeSRV:
+		priority, _ := rec.Priority.Get()
+		return fmt.Sprintf("%d %d %d %s", priority, rec.Weight, rec.Port, absoluteName(rec.Content)), nil
+	case DNSTypeTXT:
+		return quoteTXT(rec.Content), nil
+	case DNSTypeSOA:
+		return fmt.Sprintf("%s %s 1 %d %d %d %d",
+			absoluteName(rec.Content), mailbox(rec.AdminMail), rec.Refresh, rec.Retry, rec.Expire, rec.MinTTL), nil
+	}
+	return "", fmt.Errorf("can't export record %d of unknown type %s", rec.ID, rec.Type)
+}
+
+// absoluteName adds the trailing dot to a name returned by PDD, which is always absolute
+func absoluteName(name string) string {
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// mailbox converts an email address to the form of SOA RNAME: admin@domain.com is admin.domain.com.
+func mailbox(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return absoluteName(email)
+	}
+	local := strings.ReplaceAll(email[:at], ".", `\.`)
+	return local + "." + absoluteName(email[at+1:])
+}
+
+// quoteTXT returns TXT content as quoted character strings of at most 255 bytes.
+// Content which is already a sequence of quoted strings keeps its splitting.
+func quoteTXT(content string) string {
+	strs := []string{content}
+	if strings.HasPrefix(content, `"`) {
+		if s, err := splitQuoted(content); err == nil {
+			strs = s
+		}
+	}
+
+	var quoted []string
+	for _, s := range strs {
+		for {
+			chunk := s
+			if len(chunk) > maxTXTChunk {
+				chunk = chunk[:maxTXTChunk]
+			}
+			quoted = append(quoted, quoteString(chunk))
+			s = s[len(chunk):]
+			if s == "" {
+				break
+			}
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,805 @@
+package yapdd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxIncludeDepth limits nesting of $INCLUDE directives
+const maxIncludeDepth = 10
+
+// Zone is the content of a master file parsed for import into domain
+type Zone struct {
+	Domain  string
+	Records []ZoneRecord
+	// Issues are lines which can't be imported, e.g. records of unsupported types
+	Issues []ZoneIssue
+}
+
+// ZoneRecord is a record of a master file
+type ZoneRecord struct {
+	File   string
+	Line   int
+	Name   string // absolute owner name with trailing dot
+	Record Record
+}
+
+// ZoneIssue describes a line of a master file which can't be imported
+type ZoneIssue struct {
+	File   string
+	Line   int
+	Text   string
+	Reason string
+}
+
+func (i *ZoneIssue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Reason, i.Text)
+}
+
+// ZoneSyntaxError is returned when a master file can't be parsed
+type ZoneSyntaxError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e *ZoneSyntaxError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// ParseZone parses RFC 1035 master file of domain. $INCLUDE directives are not followed
+// and are reported as issues, so untrusted input can't read local files.
+func ParseZone(r io.Reader, domain string) (*Zone, error) {
+	p := newZoneParser(domain)
+	if err := p.parse(r, "-", "", 0); err != nil {
+		return nil, err
+	}
+	return p.zone, nil
+}
+
+// ParseZoneFile parses RFC 1035 master file of domain. Files of $INCLUDE directives
+// are opened relative to the directory of path; files outside of it are not read
+// and are reported as issues.
+func ParseZoneFile(path, domain string) (*Zone, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	root, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	p := newZoneParser(domain)
+	p.root = root
+	if err := p.parse(f, path, root, 0); err != nil {
+		return nil, err
+	}
+	return p.zone, nil
+}
+
+type zoneParser struct {
+	domain string // lowercase without trailing dot
+	origin string // absolute with trailing dot
+
+	ttl      uint32 // set by $TTL
+	hasTTL   bool
+	lastTTL  uint32 // TTL of the previous record, used without $TTL
+	lastName string
+
+	// root is the directory $INCLUDE may read files from, includes are disabled if empty
+	root string
+
+	zone *Zone
+}
+
+func newZoneParser(domain string) *zoneParser {
+	domain = normalizeDomain(domain)
+	return &zoneParser{
+		domain: domain,
+		origin: domain + ".",
+		zone:   &Zone{Domain: domain},
+	}
+}
+
+func (p *zoneParser) parse(r io.Reader, file, dir string, depth int) error {
+	lex := &zoneLexer{sc: bufio.NewScanner(r), file: file}
+	for {
+		e, err := lex.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !e.leadingSpace && strings.HasPrefix(e.tokens[0].text, "$") {
+			err = p.directive(e, dir, depth)
+		} else {
+			err = p.record(e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *zoneParser) directive(e *zoneEntry, dir string, depth int) error {
+	args := e.tokens[1:]
+
+	switch strings.ToUpper(e.tokens[0].text) {
+	case "$ORIGIN":
+		if len(args) != 1 {
+			return e.syntaxError("$ORIGIN requires a name")
+		}
+		p.origin = p.absolute(args[0].text)
+	case "$TTL":
+		if len(args) != 1 {
+			return e.syntaxError("$TTL requires a value")
+		}
+		ttl, ok := parseZoneTTL(args[0].text)
+		if !ok {
+			return e.syntaxError("invalid $TTL " + args[0].text)
+		}
+		p.ttl, p.hasTTL = ttl, true
+	case "$INCLUDE":
+		if len(args) < 1 || len(args) > 2 {
+			return e.syntaxError("$INCLUDE requires a file name and an optional origin")
+		}
+		if depth >= maxIncludeDepth {
+			return e.syntaxError("too many nested $INCLUDE directives")
+		}
+		return p.include(e, args, dir, depth)
+	default:
+		p.issue(e, "unsupported directive "+e.tokens[0].text)
+	}
+	return nil
+}
+
+// include parses an included file; the origin and the owner name are restored after it
+func (p *zoneParser) include(e *zoneEntry, args []zoneToken, dir string, depth int) error {
+	if p.root == "" {
+		p.issue(e, "$INCLUDE is not allowed when parsing from a reader")
+		return nil
+	}
+
+	path := args[0].text
+	if filepath.IsAbs(path) {
+		p.issue(e, "$INCLUDE of an absolute path is not allowed")
+		return nil
+	}
+	path = filepath.Join(dir, path)
+	if !inDir(p.root, path) {
+		p.issue(e, "$INCLUDE of a file outside of the zone directory is not allowed")
+		return nil
+	}
+
+	// symlinks are resolved, so a link can't point outside of the directory either
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return e.syntaxError(err.Error())
+	}
+	if !inDir(p.root, real) {
+		p.issue(e, "$INCLUDE of a file outside of the zone directory is not allowed")
+		return nil
+	}
+
+	f, err := os.Open(real)
+	if err != nil {
+		return e.syntaxError(err.Error())
+	}
+	defer f.Close()
+
+	origin, lastName := p.origin, p.lastName
+	if len(args) == 2 {
+		p.origin = p.absolute(args[1].text)
+	}
+
+	err = p.parse(f, path, filepath.Dir(real), depth+1)
+	p.origin, p.lastName = origin, lastName
+	return err
+}
+
+func (p *zoneParser) record(e *zoneEntry) error {
+	tokens := e.tokens
+	name := p.lastName
+	if !e.leadingSpace {
+		name = p.absolute(tokens[0].text)
+		tokens = tokens[1:]
+	}
+	if name == "" {
+		return e.syntaxError("record without owner name")
+	}
+	p.lastName = name
+
+	// TTL and class precede the type in any order
+	ttl, hasTTL, class := uint32(0), false, "IN"
+	for i := 0; i < 2 && len(tokens) > 0; i++ {
+		if c := strings.ToUpper(tokens[0].text); c == "IN" || c == "CH" || c == "HS" || c == "CS" {
+			class = c
+			tokens = tokens[1:]
+		} else if v, ok := parseZoneTTL(tokens[0].text); ok && !hasTTL {
+			ttl, hasTTL = v, true
+			tokens = tokens[1:]
+		}
+	}
+	if len(tokens) == 0 {
+		return e.syntaxError("record without type")
+	}
+
+	switch {
+	case hasTTL:
+		p.lastTTL = ttl
+	case p.hasTTL:
+		ttl = p.ttl
+	default:
+		ttl = p.lastTTL
+	}
+
+	if class != "IN" {
+		p.issue(e, "unsupported class "+class)
+		return nil
+	}
+
+	subdomain, ok := p.subdomain(name)
+	if !ok {
+		p.issue(e, "name "+name+" is outside of domain "+p.domain)
+		return nil
+	}
+
+	rec, err := p.typed(DNSRecordType(strings.ToUpper(tokens[0].text)), subdomain, ttl, tokens[1:])
+	if err != nil {
+		p.issue(e, err.Error())
+		return nil
+	}
+
+	p.zone.Records = append(p.zone.Records, ZoneRecord{File: e.file, Line: e.line, Name: name, Record: rec})
+	return nil
+}
+
+// typed converts rdata of a record to a typed record
+func (p *zoneParser) typed(t DNSRecordType, subdomain string, ttl uint32, rdata []zoneToken) (Record, error) {
+	fields := make([]string, len(rdata))
+	for i, tok := range rdata {
+		fields[i] = tok.text
+	}
+
+	want := func(n int) error {
+		if len(fields) != n {
+			return fmt.Errorf("%s record requires %d fields, got %d", t, n, len(fields))
+		}
+		return nil
+	}
+
+	switch t {
+	case DNSTypeA, DNSTypeAAAA:
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		ip := net.ParseIP(fields[0])
+		if t == DNSTypeA {
+			if ip == nil || ip.To4() == nil || strings.Contains(fields[0], ":") {
+				return nil, errors.New("invalid IPv4 address " + fields[0])
+			}
+			return ARecord{Subdomain: subdomain, IP: ip, TTL: ttl}, nil
+		}
+		if ip == nil || !strings.Contains(fields[0], ":") {
+			return nil, errors.New("invalid IPv6 address " + fields[0])
+		}
+		return AAAARecord{Subdomain: subdomain, IP: ip, TTL: ttl}, nil
+	case DNSTypeCNAME:
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		return CNAMERecord{Subdomain: subdomain, Target: p.absolute(fields[0]), TTL: ttl}, nil
+	case DNSTypeNS:
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		return NSRecord{Subdomain: subdomain, NameServer: p.absolute(fields[0]), TTL: ttl}, nil
+	case DNSTypeMX:
+		if err := want(2); err != nil {
+			return nil, err
+		}
+		priority, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return nil, errors.New("invalid MX preference " + fields[0])
+		}
+		return MXRecord{Subdomain: subdomain, Priority: uint16(priority), Exchange: p.absolute(fields[1]), TTL: ttl}, nil
+	case DNSTypeTXT:
+		if len(fields) == 0 {
+			return nil, errors.New("TXT record requires text")
+		}
+		// character strings are concatenated the way SPF and DKIM records are read,
+		// text with quotes or backslashes keeps the quoted form to be valid content
+		text := strings.Join(fields, "")
+		if strings.ContainsAny(text, `"\`) {
+			quoted := make([]string, len(fields))
+			for i, f := range fields {
+				quoted[i] = quoteString(f)
+			}
+			text = strings.Join(quoted, " ")
+		}
+		return TXTRecord{Subdomain: subdomain, Text: text, TTL: ttl}, nil
+	case DNSTypeSRV:
+		if err := want(4); err != nil {
+			return nil, err
+		}
+		labels := strings.SplitN(subdomain, ".", 3)
+		if len(labels) < 2 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+			return nil, errors.New("SRV record name must be _service._proto")
+		}
+		var nums [3]uint16
+		for i := range nums {
+			n, err := strconv.ParseUint(fields[i], 10, 16)
+			if err != nil {
+				return nil, errors.New("invalid SRV field " + fields[i])
+			}
+			nums[i] = uint16(n)
+		}
+		r := SRVRecord{
+			Service:  labels[0][1:],
+			Proto:    labels[1][1:],
+			Priority: nums[0],
+			Weight:   nums[1],
+			Port:     nums[2],
+			Target:   p.absolute(fields[3]),
+			TTL:      ttl,
+		}
+		if len(labels) == 3 {
+			r.Subdomain = labels[2]
+		}
+		return r, nil
+	case DNSTypeSOA:
+		if err := want(7); err != nil {
+			return nil, err
+		}
+		var nums [4]uint32
+		for i := range nums {
+			n, ok := parseZoneTTL(fields[i+3])
+			if !ok {
+				return nil, errors.New("invalid SOA field " + fields[i+3])
+			}
+			nums[i] = n
+		}
+		return SOARecord{
+			AdminMail: email(p.absolute(fields[1])),
+			Refresh:   nums[0],
+			Retry:     nums[1],
+			Expire:    nums[2],
+			NegCache:  nums[3],
+			TTL:       ttl,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported record type %s", t)
+}
+
+// inDir reports whether path is inside of dir
+func inDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// absolute resolves name relative to the current origin
+func (p *zoneParser) absolute(name string) string {
+	switch {
+	case name == "@":
+		return p.origin
+	case strings.HasSuffix(name, ".") && !strings.HasSuffix(name, `\.`):
+		return strings.ToLower(name)
+	case p.origin == ".":
+		return strings.ToLower(name) + "."
+	}
+	return strings.ToLower(name) + "." + p.origin
+}
+
+// subdomain returns absolute name relative to the domain
+func (p *zoneParser) subdomain(name string) (string, bool) {
+	name = strings.TrimSuffix(name, ".")
+	if name == p.domain {
+		return "@", true
+	}
+	if strings.HasSuffix(name, "."+p.domain) {
+		return strings.TrimSuffix(name, "."+p.domain), true
+	}
+	return "", false
+}
+
+func (p *zoneParser) issue(e *zoneEntry, reason string) {
+	p.zone.Issues = append(p.zone.Issues, ZoneIssue{File: e.file, Line: e.line, Text: e.text(), Reason: reason})
+}
+
+// email converts SOA RNAME to an email address: first\.last.domain.com. is first.last@domain.com
+func email(mailbox string) string {
+	mailbox = strings.TrimSuffix(mailbox, ".")
+	for i := 0; i < len(mailbox); i++ {
+		switch mailbox[i] {
+		case '\\':
+			i++
+		case '.':
+			return strings.ReplaceAll(mailbox[:i], `\.`, ".") + "@" + mailbox[i+1:]
+		}
+	}
+	return mailbox
+}
+
+// parseZoneTTL parses TTL in seconds or with units like 1h30m
+func parseZoneTTL(s string) (uint32, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if v, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return uint32(v), true
+	}
+
+	var total, n uint64
+	digits := false
+	for _, c := range strings.ToLower(s) {
+		if c >= '0' && c <= '9' {
+			n = n*10 + uint64(c-'0')
+			digits = true
+			continue
+		}
+
+		var unit uint64
+		switch c {
+		case 's':
+			unit = 1
+		case 'm':
+			unit = 60
+		case 'h':
+			unit = 3600
+		case 'd':
+			unit = 86400
+		case 'w':
+			unit = 604800
+		default:
+			return 0, false
+		}
+		if !digits {
+			return 0, false
+		}
+		total += n * unit
+		n, digits = 0, false
+	}
+	if digits || total > 1<<32-1 {
+		return 0, false
+	}
+	return uint32(total), true
+}
+
+type zoneToken struct {
+	text   string
+	quoted bool
+}
+
+// zoneEntry is a logical line of a master file with parentheses joined
+type zoneEntry struct {
+	file         string
+	line         int
+	leadingSpace bool
+	tokens       []zoneToken
+}
+
+func (e *zoneEntry) text() string {
+	s := make([]string, len(e.tokens))
+	for i, t := range e.tokens {
+		s[i] = t.text
+		if t.quoted {
+			s[i] = strconv.Quote(t.text)
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+func (e *zoneEntry) syntaxError(msg string) error {
+	return &ZoneSyntaxError{File: e.file, Line: e.line, Message: msg}
+}
+
+type zoneLexer struct {
+	sc   *bufio.Scanner
+	file string
+	line int
+}
+
+// next returns the next entry with at least one token or io.EOF
+func (l *zoneLexer) next() (*zoneEntry, error) {
+	for {
+		if !l.sc.Scan() {
+			if err := l.sc.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		l.line++
+
+		line := l.sc.Text()
+		e := &zoneEntry{
+			file:         l.file,
+			line:         l.line,
+			leadingSpace: line != "" && (line[0] == ' ' || line[0] == '\t'),
+		}
+
+		depth := 0
+		for {
+			var err error
+			if depth, err = l.tokenize(e, line, depth); err != nil {
+				return nil, err
+			}
+			if depth == 0 {
+				break
+			}
+			if !l.sc.Scan() {
+				return nil, e.syntaxError("unbalanced parentheses")
+			}
+			l.line++
+			line = l.sc.Text()
+		}
+
+		if len(e.tokens) > 0 {
+			return e, nil
+		}
+	}
+}
+
+// tokenize appends tokens of line to e and returns the depth of parentheses after it
+func (l *zoneLexer) tokenize(e *zoneEntry, line string, depth int) (int, error) {
+	var cur strings.Builder
+	inToken := false
+	flush := func() {
+		if inToken {
+			e.tokens = append(e.tokens, zoneToken{text: cur.String()})
+			cur.Reset()
+			inToken = false
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ';':
+			flush()
+			return depth, nil
+		case c == ' ' || c == '\t' || c == '\r':
+			flush()
+		case c == '(':
+			flush()
+			depth++
+		case c == ')':
+			flush()
+			if depth == 0 {
+				return 0, &ZoneSyntaxError{File: l.file, Line: l.line, Message: "unbalanced parentheses"}
+			}
+			depth--
+		case c == '"':
+			flush()
+			s, n, err := unquoteZone(line[i+1:])
+			if err != nil {
+				return 0, &ZoneSyntaxError{File: l.file, Line: l.line, Message: err.Error()}
+			}
+			e.tokens = append(e.tokens, zoneToken{text: s, quoted: true})
+			i += n
+		case c == '\\' && i+1 < len(line):
+			// escaped characters are kept in names as is
+			cur.WriteByte(c)
+			cur.WriteByte(line[i+1])
+			inToken = true
+			i++
+		default:
+			cur.WriteByte(c)
+			inToken = true
+		}
+	}
+	flush()
+	return depth, nil
+}
+
+// unquoteZone reads a quoted string after the opening quote and returns its value
+// and the number of bytes read including the closing quote
+func unquoteZone(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			return b.String(), i + 1, nil
+		case c == '\\' && i+3 < len(s) && isDigits(s[i+1:i+4]):
+			n, _ := strconv.Atoi(s[i+1 : i+4])
+			if n > 255 {
+				return "", 0, errors.New("invalid escape \\" + s[i+1:i+4])
+			}
+			b.WriteByte(byte(n))
+			i += 3
+		case c == '\\' && i+1 < len(s):
+			b.WriteByte(s[i+1])
+			i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, errors.New("unterminated quoted string")
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ImportAction tells what ApplyZoneImport does with a record
+type ImportAction string
+
+const (
+	ImportCreate ImportAction = "create"
+	ImportSkip   ImportAction = "skip"
+)
+
+// ImportStep is a record of the zone with parameters of DNSAdd
+type ImportStep struct {
+	Record ZoneRecord
+	Params *DNSRequestParams
+	Action ImportAction
+	// Reason explains a skipped record or a changed TTL of a created one
+	Reason string
+}
+
+// ImportPlan is a list of steps importing a zone into domain
+type ImportPlan struct {
+	Domain string
+	Steps  []ImportStep
+	Issues []ZoneIssue // lines of the zone which can't be imported
+}
+
+// ImportResult is a result of a step of ImportPlan
+type ImportResult struct {
+	Step     ImportStep
+	Response *DNSResponse
+	Err      error
+	// Attempted is false for skipped steps and for steps not started because the context was done
+	Attempted bool
+}
+
+// ImportSummary is returned by ApplyZoneImport
+type ImportSummary struct {
+	Results      []ImportResult
+	Created      int
+	Skipped      int
+	Failed       int
+	NotAttempted int
+}
+
+func (s *ImportSummary) String() string {
+	return fmt.Sprintf("%d created, %d skipped, %d failed, %d not attempted", s.Created, s.Skipped, s.Failed, s.NotAttempted)
+}
+
+// PlanZoneImport compares records of zone with records of the domain returned by DNSList.
+// Records which already exist or are repeated in the zone, SOA and NS records of the domain itself,
+// which are managed by PDD, and records failing validation are skipped. TTL is moved into
+// the range PDD accepts.
+func (c *Client) PlanZoneImport(ctx context.Context, zone *Zone) (*ImportPlan, error) {
+	list, err := c.DNSList(ctx, zone.Domain)
+	if err != nil {
+		return nil, err
+	}
+	return planZoneImport(zone, list.Records), nil
+}
+
+func planZoneImport(zone *Zone, existing []*DNSRecord) *ImportPlan {
+	plan := &ImportPlan{Domain: zone.Domain, Issues: zone.Issues}
+
+	// records to be created with lines they come from, to skip repeated ones
+	var planned []*DNSRecord
+	var plannedLines []int
+
+	for _, zr := range zone.Records {
+		t := zr.Record.Type()
+		step := ImportStep{Record: zr, Params: zr.Record.Params(), Action: ImportSkip}
+		subdomain, _ := step.Params.GetSubdomain()
+
+		if ttl, ok := step.Params.GetTTL(); ok && (ttl < MinDNSTTL || ttl > MaxDNSTTL) {
+			fixed := uint32(MinDNSTTL)
+			if ttl > MaxDNSTTL {
+				fixed = MaxDNSTTL
+			}
+			step.Params.TTL(fixed)
+			step.Reason = fmt.Sprintf("ttl %d changed to %d", ttl, fixed)
+		}
+
+		key := recordKey(t, step.Params, "content", "target", "priority")
+		switch {
+		case t == DNSTypeSOA:
+			step.Reason = "SOA record is managed by PDD"
+		case t == DNSTypeNS && normalizeSubdomain(subdomain) == "@":
+			step.Reason = "NS records of the domain are managed by PDD"
+		case findRecord(existing, key) >= 0:
+			step.Reason = "record already exists"
+		case findRecord(planned, key) >= 0:
+			step.Reason = fmt.Sprintf("duplicate of line %d", plannedLines[findRecord(planned, key)])
+		default:
+			if err := step.Params.Validate(t); err != nil {
+				step.Reason = err.Error()
+				break
+			}
+
+			step.Action = ImportCreate
+			rec := &DNSRecord{Domain: zone.Domain}
+			rec.apply(key)
+			planned = append(planned, rec)
+			plannedLines = append(plannedLines, zr.Line)
+		}
+
+		plan.Steps = append(plan.Steps, step)
+	}
+	return plan
+}
+
+func findRecord(records []*DNSRecord, values url.Values) int {
+	for i, rec := range records {
+		if rec.matches(values) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ApplyZoneImport adds records of steps with ImportCreate by DNSAdd as a Batch.
+// The error is *BatchError if any record failed or was not attempted.
+func (c *Client) ApplyZoneImport(ctx context.Context, plan *ImportPlan, opts BatchOptions) (*ImportSummary, error) {
+	return applyZoneImport(ctx, c.NewBatch(opts), plan)
+}
+
+func applyZoneImport(ctx context.Context, b *Batch, plan *ImportPlan) (*ImportSummary, error) {
+	summary := &ImportSummary{Results: make([]ImportResult, len(plan.Steps))}
+
+	var steps []int
+	for i, step := range plan.Steps {
+		summary.Results[i].Step = step
+		if step.Action != ImportCreate {
+			summary.Skipped++
+			continue
+		}
+		b.Add(plan.Domain, step.Record.Record.Type(), step.Params)
+		steps = append(steps, i)
+	}
+
+	results, err := b.Run(ctx)
+	for i, r := range results {
+		res := &summary.Results[steps[i]]
+		res.Response, res.Err, res.Attempted = r.Response, r.Err, r.Attempted
+		switch {
+		case !r.Attempted:
+			summary.NotAttempted++
+		case r.Err != nil:
+			summary.Failed++
+		default:
+			summary.Created++
+		}
+	}
+	return summary, err
+}
+
+func (m *MultiClient) PlanZoneImport(ctx context.Context, zone *Zone) (*ImportPlan, error) {
+	cli, err := m.Client(zone.Domain)
+	if err != nil {
+		return nil, err
+	}
+	return cli.PlanZoneImport(ctx, zone)
+}
+
+func (m *MultiClient) ApplyZoneImport(ctx context.Context, plan *ImportPlan, opts BatchOptions) (*ImportSummary, error) {
+	return applyZoneImport(ctx, m.NewBatch(opts), plan)
+}
@@ -0,0 +1,306 @@
+package yapdd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseZone(t *testing.T) {
+	zone := `$ORIGIN domain.com.
+$TTL 1h
+@	IN	SOA	ns1 hostmaster\.team.domain.com. (
+		2024010101 ; serial
+		4h         ; refresh
+		15m        ; retry
+		2w         ; expire
+		1d )       ; minimum
+	IN	NS	ns1
+www	900	IN	A	127.0.0.1
+	IN 1800	AAAA	2001:db8::1
+mail	CNAME	domain.mail.yandex.net.
+@	MX	10 mx.yandex.net.
+@	TXT	"v=spf1 redirect=_spf.yandex.net"
+dkim._domainkey	TXT	( "v=DKIM1; k=rsa; "
+		"p=MIGf" )
+_xmpp-client._tcp.chat	SRV	20 5 5222 xmpp
+$ORIGIN dev.domain.com.
+*	A	127.0.0.2
+ptr	PTR	host.domain.com.
+other.com.	A	127.0.0.3
+bad	A	::1
+$GENERATE 1-2 host$ A 127.0.0.$
+$INCLUDE /etc/passwd
+`
+	z, err := ParseZone(strings.NewReader(zone), "Domain.com.")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expRecords := []ZoneRecord{
+		{File: "-", Line: 3, Name: "domain.com.", Record: SOARecord{
+			AdminMail: "hostmaster.team@domain.com", Refresh: 14400, Retry: 900, Expire: 1209600, NegCache: 86400, TTL: 3600,
+		}},
+		{File: "-", Line: 9, Name: "domain.com.", Record: NSRecord{Subdomain: "@", NameServer: "ns1.domain.com.", TTL: 3600}},
+		{File: "-", Line: 10, Name: "www.domain.com.", Record: ARecord{Subdomain: "www", IP: net.ParseIP("127.0.0.1"), TTL: 900}},
+		{File: "-", Line: 11, Name: "www.domain.com.", Record: AAAARecord{Subdomain: "www", IP: net.ParseIP("2001:db8::1"), TTL: 1800}},
+		{File: "-", Line: 12, Name: "mail.domain.com.", Record: CNAMERecord{Subdomain: "mail", Target: "domain.mail.yandex.net.", TTL: 3600}},
+		{File: "-", Line: 13, Name: "domain.com.", Record: MXRecord{Subdomain: "@", Priority: 10, Exchange: "mx.yandex.net.", TTL: 3600}},
+		{File: "-", Line: 14, Name: "domain.com.", Record: TXTRecord{Subdomain: "@", Text: "v=spf1 redirect=_spf.yandex.net", TTL: 3600}},
+		{File: "-", Line: 15, Name: "dkim._domainkey.domain.com.", Record: TXTRecord{
+			Subdomain: "dkim._domainkey", Text: "v=DKIM1; k=rsa; p=MIGf", TTL: 3600,
+		}},
+		{File: "-", Line: 17, Name: "_xmpp-client._tcp.chat.domain.com.", Record: SRVRecord{
+			Service: "xmpp-client", Proto: "tcp", Subdomain: "chat", Priority: 20, Weight: 5, Port: 5222, Target: "xmpp.domain.com.", TTL: 3600,
+		}},
+		{File: "-", Line: 19, Name: "*.dev.domain.com.", Record: ARecord{Subdomain: "*.dev", IP: net.ParseIP("127.0.0.2"), TTL: 3600}},
+	}
+	expIssues := []ZoneIssue{
+		{File: "-", Line: 20, Text: "ptr PTR host.domain.com.", Reason: "unsupported record type PTR"},
+		{File: "-", Line: 21, Text: "other.com. A 127.0.0.3", Reason: "name other.com. is outside of domain domain.com"},
+		{File: "-", Line: 22, Text: "bad A ::1", Reason: "invalid IPv4 address ::1"},
+		{File: "-", Line: 23, Text: "$GENERATE 1-2 host$ A 127.0.0.$", Reason: "unsupported directive $GENERATE"},
+		{File: "-", Line: 24, Text: "$INCLUDE /etc/passwd", Reason: "$INCLUDE is not allowed when parsing from a reader"},
+	}
+
+	if z.Domain != "domain.com" {
+		t.Errorf("expected domain domain.com, got %s", z.Domain)
+	}
+	if !reflect.DeepEqual(z.Records, expRecords) {
+		t.Errorf("expected records:\n%#v\ngot:\n%#v", expRecords, z.Records)
+	}
+	if !reflect.DeepEqual(z.Issues, expIssues) {
+		t.Errorf("expected issues:\n%#v\ngot:\n%#v", expIssues, z.Issues)
+	}
+}
+
+func TestParseZone_Errors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		zone   string
+		expErr string
+	}{
+		{
+			name:   "unbalanced open parenthesis",
+			zone:   "@ SOA ns1 admin (\n1 2 3 4 5\n",
+			expErr: "-:1: unbalanced parentheses",
+		},
+		{
+			name:   "unbalanced close parenthesis",
+			zone:   "\nwww A 127.0.0.1 )\n",
+			expErr: "-:2: unbalanced parentheses",
+		},
+		{
+			name:   "unterminated quote",
+			zone:   "@ TXT \"v=spf1\n",
+			expErr: "-:1: unterminated quoted string",
+		},
+		{
+			name:   "invalid TTL",
+			zone:   "$TTL 1x\n",
+			expErr: "-:1: invalid $TTL 1x",
+		},
+		{
+			name:   "no owner",
+			zone:   "  A 127.0.0.1\n",
+			expErr: "-:1: record without owner name",
+		},
+		{
+			name:   "no type",
+			zone:   "www 900 IN\n",
+			expErr: "-:1: record without type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseZone(strings.NewReader(tc.zone), "domain.com")
+			var syntaxErr *ZoneSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected *ZoneSyntaxError, got %v", err)
+			}
+			if err.Error() != tc.expErr {
+				t.Errorf("expected error %q, got %q", tc.expErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseZoneFile_Include(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "yapdd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// paths are compared with symlinks resolved, as TempDir may be a symlink
+	if tmp, err = filepath.EvalSymlinks(tmp); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(tmp, "zones")
+	secret := filepath.Join(tmp, "secret.zone")
+
+	files := map[string]string{
+		"zones/domain.com.zone": "$TTL 3600\n$INCLUDE hosts.zone dev\nwww A 127.0.0.1\n" +
+			"$INCLUDE ../secret.zone\n$INCLUDE " + secret + "\n$INCLUDE link.zone\n",
+		"zones/hosts.zone": "api A 127.0.0.2\n",
+		"secret.zone":      "secret A 127.0.0.3\n",
+	}
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(tmp, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(secret, filepath.Join(dir, "link.zone")); err != nil {
+		t.Fatal(err)
+	}
+
+	zoneFile := filepath.Join(dir, "domain.com.zone")
+	z, err := ParseZoneFile(zoneFile, "domain.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expRecords := []ZoneRecord{
+		{File: filepath.Join(dir, "hosts.zone"), Line: 1, Name: "api.dev.domain.com.", Record: ARecord{
+			Subdomain: "api.dev", IP: net.ParseIP("127.0.0.2"), TTL: 3600,
+		}},
+		{File: zoneFile, Line: 3, Name: "www.domain.com.", Record: ARecord{
+			Subdomain: "www", IP: net.ParseIP("127.0.0.1"), TTL: 3600,
+		}},
+	}
+	outside := "$INCLUDE of a file outside of the zone directory is not allowed"
+	expIssues := []ZoneIssue{
+		{File: zoneFile, Line: 4, Text: "$INCLUDE ../secret.zone", Reason: outside},
+		{File: zoneFile, Line: 5, Text: "$INCLUDE " + secret, Reason: "$INCLUDE of an absolute path is not allowed"},
+		{File: zoneFile, Line: 6, Text: "$INCLUDE link.zone", Reason: outside},
+	}
+	if !reflect.DeepEqual(z.Records, expRecords) {
+		t.Errorf("expected records:\n%#v\ngot:\n%#v", expRecords, z.Records)
+	}
+	if !reflect.DeepEqual(z.Issues, expIssues) {
+		t.Errorf("expected issues:\n%#v\ngot:\n%#v", expIssues, z.Issues)
+	}
+}
+
+func TestParseZone_Export(t *testing.T) {
+	records := []*DNSRecord{
+		{Type: DNSTypeA, Subdomain: "www", Content: "127.0.0.1", TTL: 900},
+		{Type: DNSTypeTXT, Subdomain: "@", Content: strings.Repeat("a", 300), TTL: 21600},
+		{Type: DNSTypeTXT, Subdomain: "quoted", Content: `"say \"hi\"" "to C:\\dir"`, TTL: 21600},
+		{Type: DNSTypeMX, Subdomain: "@", Content: "mx.yandex.net.", Priority: DNSPriority{value: 10, ok: true}, TTL: 21600},
+	}
+
+	var buf bytes.Buffer
+	if err := writeZone(&buf, "domain.com", records); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	z, err := ParseZone(&buf, "domain.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(z.Records) != len(records) || len(z.Issues) != 0 {
+		t.Fatalf("expected %d records without issues, got %#v", len(records), z)
+	}
+	for _, zr := range z.Records {
+		values := recordKey(zr.Record.Type(), zr.Record.Params(), "content", "target", "priority", "ttl")
+		if findRecord(records, values) < 0 {
+			t.Errorf("record %#v is not exported", zr.Record)
+		}
+	}
+
+	for _, step := range planZoneImport(z, nil).Steps {
+		if step.Action != ImportCreate {
+			t.Errorf("record %#v is not created: %s", step.Record.Record, step.Reason)
+		}
+	}
+}
+
+func TestClient_ZoneImport(t *testing.T) {
+	const (
+		listURL = "GET https://pddimp.yandex.ru/api2/admin/dns/list?domain=domain.com"
+		addURL  = "POST https://pddimp.yandex.ru/api2/admin/dns/add"
+	)
+
+	zone := `$TTL 300
+@	SOA	ns1 admin 1 2 3 4 5
+@	NS	dns1.yandex.net.
+@	MX	10 mx.yandex.net.
+www	A	127.0.0.1
+www	A	127.0.0.1
+api	A	127.0.0.2
+long	86400000	TXT	"text"
+bad	CNAME	-bad-
+`
+	z, err := ParseZone(strings.NewReader(zone), "domain.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	transport := &sequenceTransportMock{responses: []mockResponse{
+		{status: http.StatusOK, body: `{"domain": "domain.com", "records": [
+			{"record_id": 1, "type": "MX", "subdomain": "@", "content": "mx.yandex.net.", "ttl": 21600, "priority": 10}
+		], "success": "ok"}`},
+		{status: http.StatusOK, body: `{"domain": "domain.com", "record": {"record_id": 2, "type": "A", "subdomain": "www", "content": "127.0.0.1"}, "success": "ok"}`},
+		{status: http.StatusOK, body: `{"domain": "domain.com", "success": "error", "error": "unknown"}`},
+		{status: http.StatusOK, body: `{"domain": "domain.com", "record": {"record_id": 3, "type": "TXT", "subdomain": "long", "content": "text"}, "success": "ok"}`},
+	}}
+	cli := New("token", WithHTTPClient(&http.Client{Transport: transport}))
+
+	plan, err := cli.PlanZoneImport(context.Background(), z)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expSteps := []struct {
+		action ImportAction
+		reason string
+	}{
+		{ImportSkip, "SOA record is managed by PDD"},
+		{ImportSkip, "NS records of the domain are managed by PDD"},
+		{ImportSkip, "record already exists"},
+		{ImportCreate, "ttl 300 changed to 900"},
+		{ImportSkip, "duplicate of line 5"},
+		{ImportCreate, "ttl 300 changed to 900"},
+		{ImportCreate, "ttl 86400000 changed to 1209600"},
+		{ImportSkip, `invalid CNAME record: content "-bad-.domain.com.": must be a hostname`},
+	}
+	if len(plan.Steps) != len(expSteps) {
+		t.Fatalf("expected %d steps, got %d", len(expSteps), len(plan.Steps))
+	}
+	for i, exp := range expSteps {
+		if step := plan.Steps[i]; step.Action != exp.action || step.Reason != exp.reason {
+			t.Errorf("step %d: expected %s %q, got %s %q", i, exp.action, exp.reason, step.Action, step.Reason)
+		}
+	}
+
+	summary, err := cli.ApplyZoneImport(context.Background(), plan, BatchOptions{Parallelism: 1})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || !errors.Is(err, ErrUnknown) {
+		t.Fatalf("expected *BatchError with ErrUnknown, got %v", err)
+	}
+
+	if s := summary.String(); s != "2 created, 5 skipped, 1 failed, 0 not attempted" {
+		t.Errorf("unexpected summary: %s", s)
+	}
+	if summary.Results[3].Response.Record.ID != 2 || summary.Results[5].Err == nil || !summary.Results[6].Attempted {
+		t.Errorf("unexpected results: %#v", summary.Results)
+	}
+
+	expRequests := []string{listURL, addURL, addURL, addURL}
+	if !reflect.DeepEqual(transport.requests, expRequests) {
+		t.Errorf("expected requests %v, got %v", expRequests, transport.requests)
+	}
+}
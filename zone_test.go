@@ -0,0 +1,56 @@
+package yapdd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_ExportZone(t *testing.T) {
+	longTXT := strings.Repeat("a", 300)
+	transport := &httpTransportMock{
+		response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"domain": "domain.com", "records": [
+				{"record_id": 1, "type": "A", "subdomain": "www", "content": "127.0.0.1", "ttl": 900, "priority": ""},
+				{"record_id": 2, "type": "MX", "subdomain": "@", "content": "mx.yandex.net", "ttl": 21600, "priority": 10},
+				{"record_id": 3, "type": "TXT", "subdomain": "@", "content": "v=spf1 redirect=_spf.yandex.net", "ttl": 21600, "priority": ""},
+				{"record_id": 4, "type": "SRV", "subdomain": "_xmpp-client._tcp", "content": "xmpp.domain.com.", "ttl": 21600,
+				 "priority": 20, "weight": 5, "port": 5222},
+				{"record_id": 5, "type": "NS", "subdomain": "@", "content": "dns1.yandex.net.", "ttl": 21600, "priority": ""},
+				{"record_id": 6, "type": "TXT", "subdomain": "mail._domainkey", "content": "` + longTXT + `", "ttl": 21600, "priority": ""},
+				{"record_id": 7, "type": "TXT", "subdomain": "quoted", "content": "\"say \\\"hi\\\"\" \"\\\\ok\"", "ttl": 21600, "priority": ""},
+				{"record_id": 8, "type": "SOA", "subdomain": "@", "content": "dns1.yandex.net.", "ttl": 21600, "priority": "",
+				 "admin_mail": "first.last@domain.com", "refresh": 14400, "retry": 900, "expire": 1209600, "minttl": 14400},
+				{"record_id": 9, "type": "CNAME", "subdomain": "mail", "content": "domain.mail.yandex.net.", "ttl": 21600, "priority": ""},
+				{"record_id": 10, "type": "AAAA", "subdomain": "*.Dev", "content": "2001:db8::1", "ttl": 21600, "priority": ""}
+			], "success": "ok"}`)),
+		},
+	}
+	cli := New("token", WithHTTPClient(&http.Client{Transport: transport}))
+
+	var buf bytes.Buffer
+	if err := cli.ExportZone(context.Background(), "domain.com", &buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expZone := `$ORIGIN domain.com.
+$TTL 21600
+@				IN	SOA	dns1.yandex.net. first\.last.domain.com. 1 14400 900 1209600 14400
+@				IN	NS	dns1.yandex.net.
+@				IN	MX	10 mx.yandex.net.
+@				IN	TXT	"v=spf1 redirect=_spf.yandex.net"
+mail._domainkey			IN	TXT	"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"
+_xmpp-client._tcp		IN	SRV	20 5 5222 xmpp.domain.com.
+*.dev				IN	AAAA	2001:db8::1
+mail				IN	CNAME	domain.mail.yandex.net.
+quoted				IN	TXT	"say \"hi\"" "\\ok"
+www			900	IN	A	127.0.0.1
+`
+	if buf.String() != expZone {
+		t.Errorf("expected zone:\n%s\ngot:\n%s", expZone, buf.String())
+	}
+}